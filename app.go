@@ -7,9 +7,11 @@ import (
 	"log/slog"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-toho/toho/app"
+	"github.com/go-toho/toho/config"
 	"github.com/go-toho/toho/pkg/xos"
 )
 
@@ -31,12 +33,13 @@ type TohoApp[C, L any] struct {
 	ctx     context.Context
 	cancel  func()
 	appInfo *app.App
-	mu      sync.Mutex
+	mu      sync.Mutex // serializes Start, Stop and Wait
 
 	core      Core
-	bootstrap bool
+	bootstrap atomic.Bool
 
 	config C
+	value  *config.Value[C]
 	log    L
 }
 
@@ -65,23 +68,37 @@ func NewCL[C, L any](opts ...Option) *TohoApp[C, L] {
 	}
 
 	ctx, cancel := context.WithCancel(o.ctx)
-	return &TohoApp[C, L]{
+	a := &TohoApp[C, L]{
 		opts:    o,
 		ctx:     ctx,
 		cancel:  cancel,
 		appInfo: app.New(o.appInfoOpts...),
 		core:    o.core,
 	}
+	a.value = config.NewValue(*a.backingConfig())
+	return a
 }
 
 func (a *TohoApp[C, L]) AppInfo() app.Info {
 	return a.appInfo
 }
 
+// Config returns a copy of the backing config before the app starts, and
+// of the current config once started. It does not lock, so components can
+// call it while the core initializes.
 func (a *TohoApp[C, L]) Config() C {
+	if a.bootstrap.Load() {
+		return a.value.Load()
+	}
 	return *a.backingConfig()
 }
 
+// ConfigValue returns the current config, loaded on start and swapped by
+// reloads. Its Load is lock-free, for long-running components.
+func (a *TohoApp[C, L]) ConfigValue() *config.Value[C] {
+	return a.value
+}
+
 func (a *TohoApp[C, L]) Logger() L {
 	return a.log
 }
@@ -92,7 +109,7 @@ func (a *TohoApp[C, L]) Start() error {
 		a.mu.Lock()
 		defer a.mu.Unlock()
 
-		if a.bootstrap {
+		if a.bootstrap.Load() {
 			return errAlreadyStarted
 		}
 
@@ -111,22 +128,34 @@ func (a *TohoApp[C, L]) Start() error {
 		coreOpts := &CoreOptions{
 			App:           *a.appInfo,
 			ConfigPointer: cfg,
+			ConfigLoader:  a.opts.configLoader,
+			ConfigValue:   a.value,
 			LogPointer:    &a.log,
 			Options:       a.opts.options,
 			StartTimeout:  a.opts.startTimeout,
 			StopTimeout:   a.opts.stopTimeout,
 		}
 
-		if err := a.core.Init(coreOpts); err != nil {
+		// the core may store the config it resolved in the value, which
+		// then wins over the backing config
+		var resolved bool
+		cancel := a.value.Subscribe(func(_, _ C) { resolved = true })
+		err = a.core.Init(coreOpts)
+		cancel()
+		if err != nil {
 			return fmt.Errorf("%s: %w", reflect.TypeOf(a.core), err)
 		}
 
+		if loaded := *a.backingConfig(); !resolved && !reflect.DeepEqual(a.value.Load(), loaded) {
+			a.value.Store(loaded)
+		}
+
 		// fallback logger
 		if log, ok := any(a.log).(*slog.Logger); ok && log == nil {
 			a.log = any(slog.Default()).(L)
 		}
 
-		a.bootstrap = true
+		a.bootstrap.Store(true)
 		return nil
 	}(); err != nil {
 		return err
@@ -176,7 +205,7 @@ func (a *TohoApp[C, L]) Stop() error {
 		a.mu.Lock()
 		defer a.mu.Unlock()
 
-		if !a.bootstrap {
+		if !a.bootstrap.Load() {
 			return errNotStarted
 		}
 		return nil
@@ -210,7 +239,7 @@ func (a *TohoApp[C, L]) Wait() <-chan error {
 
 	ch := make(chan error, 1)
 
-	if !a.bootstrap {
+	if !a.bootstrap.Load() {
 		ch <- errNotStarted
 		return ch
 	}
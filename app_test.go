@@ -3,15 +3,22 @@ package toho_test
 import (
 	"context"
 	"errors"
+	"io"
+	"log/slog"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/cristalhq/aconfig"
 	"go.uber.org/fx"
 
 	"github.com/go-toho/toho"
 	"github.com/go-toho/toho/app"
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/config/configfx"
+	"github.com/go-toho/toho/contrib/config/aconfigo"
+	"github.com/go-toho/toho/tohofx"
 )
 
 var errInitFailed = errors.New("init failed")
@@ -140,6 +147,34 @@ func TestConfigExposesBackingConfig(t *testing.T) {
 	}
 }
 
+func TestConfigDuringInitDoesNotBlock(t *testing.T) {
+	var app *toho.TohoApp[testConfig, *slog.Logger]
+	app = toho.NewC[testConfig](
+		toho.AppCore(fakeCore{
+			init: func(opts *toho.CoreOptions) {
+				opts.ConfigPointer.(*testConfig).Name = "loaded"
+				if got := app.Config().Name; got != "loaded" {
+					t.Errorf("Config().Name during init = %q, want loaded", got)
+				}
+			},
+		}),
+	)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.Start()
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Start() error = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start() timed out; Config() blocked during init")
+	}
+}
+
 func TestConfigOptionUsesExternalBackingConfig(t *testing.T) {
 	cfg := &testConfig{Name: "initial"}
 	app := toho.NewC[testConfig](
@@ -174,6 +209,41 @@ func TestConfigOptionUsesExternalBackingConfig(t *testing.T) {
 	}
 }
 
+func TestConfigKeepsSuppliedConfig(t *testing.T) {
+	cfg := testConfig{Name: "supplied"}
+	app := toho.NewC[testConfig](
+		toho.AppCore(tohofx.NewCore()),
+		toho.Options(
+			fx.NopLogger,
+			fx.Supply(slog.New(slog.NewTextHandler(io.Discard, nil))),
+			configfx.SupplyConfig(&cfg),
+		),
+	)
+
+	var stored []testConfig
+	cancel := app.ConfigValue().Subscribe(func(_, new testConfig) {
+		stored = append(stored, new)
+	})
+	defer cancel()
+
+	if err := app.Start(); err != nil {
+		t.Fatalf("Start() error = %v, want nil", err)
+	}
+	defer app.Stop()
+
+	if got := app.Config().Name; got != "supplied" {
+		t.Fatalf("Config().Name = %q, want supplied", got)
+	}
+	if got := app.ConfigValue().Load().Name; got != "supplied" {
+		t.Fatalf("ConfigValue().Load().Name = %q, want supplied", got)
+	}
+	for _, new := range stored {
+		if new.Name != "supplied" {
+			t.Fatalf("stored configs = %+v, want only the supplied one", stored)
+		}
+	}
+}
+
 type otherConfig struct {
 	Name string
 }
@@ -284,3 +354,66 @@ func TestLifecycleHooksRunInOrder(t *testing.T) {
 		}
 	}
 }
+
+type loadedConfig struct {
+	Name string `default:"orders"`
+	Port int    `default:"8080" validate:"min=1"`
+}
+
+func TestDefaultCoreLoadsConfig(t *testing.T) {
+	loader := aconfigo.NewLoader().
+		WithAppName("orders").
+		WithConfig(aconfig.Config{
+			Envs: []string{"ORDERS_PORT=9000"},
+			Args: []string{},
+		})
+
+	var (
+		app  *toho.TohoApp[loadedConfig, *slog.Logger]
+		port int
+	)
+	app = toho.NewC[loadedConfig](
+		toho.ConfigLoader(loader),
+		toho.BeforeStart(func(context.Context) error {
+			port = app.Config().Port
+			return nil
+		}),
+	)
+
+	if err := app.Start(); err != nil {
+		t.Fatalf("Start() error = %v, want nil", err)
+	}
+
+	if port != 9000 {
+		t.Fatalf("Config().Port in BeforeStart = %d, want 9000", port)
+	}
+	if got := app.Config().Name; got != "orders" {
+		t.Fatalf("Config().Name = %q, want orders", got)
+	}
+	if got := app.ConfigValue().Load().Port; got != 9000 {
+		t.Fatalf("ConfigValue().Load().Port = %d, want 9000", got)
+	}
+}
+
+func TestDefaultCoreValidatesLoadedConfig(t *testing.T) {
+	app := toho.NewC[loadedConfig](
+		toho.ConfigLoader(config.LoadFunc(func(dst any) error {
+			dst.(*loadedConfig).Port = -1
+			return nil
+		})),
+	)
+
+	var verr *config.ValidationError
+	if err := app.Start(); !errors.As(err, &verr) {
+		t.Fatalf("Start() error = %v, want validation error", err)
+	}
+}
+
+func TestDefaultCoreRequiresConfigLoader(t *testing.T) {
+	app := toho.NewC[testConfig]()
+
+	err := app.Start()
+	if err == nil || !strings.Contains(err.Error(), "unsupported config type") {
+		t.Fatalf("Start() error = %v, want unsupported config type", err)
+	}
+}
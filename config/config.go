@@ -6,6 +6,12 @@ import (
 	"reflect"
 )
 
+// Loader loads config, from defaults, files, env vars, flags or any other
+// source, into the struct pointed to by dst.
+type Loader interface {
+	Load(dst any) error
+}
+
 func StructCheck(structure any) error {
 	if structure == nil {
 		return errors.New("config: struct nil")
@@ -0,0 +1,189 @@
+// Package configcmd implements the "config" subcommand of applications,
+// which inspects the application config without starting it:
+//
+//	app config print [--sources] [--format text|json]
+//	app config schema
+//	app config docs [--format markdown|env]
+//	app config encrypt [--recipient age1...] [--recipients-file path] [value]
+//	app config rotate [--recipient age1...] [--recipients-file path] file...
+//
+// The encrypt and rotate commands decrypt with the keys of config.AgeKeys,
+// held by the env vars of the EnvPrefix of the command, and encrypt for
+// the recipients, or else for the keys.
+//
+// Applications dispatch to it before starting:
+//
+//	cmd := &configcmd.Command{Config: &Config{}, Loader: loader}
+//	if handled, err := cmd.Handle(os.Args[1:]); handled {
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		return
+//	}
+package configcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-toho/toho/config"
+)
+
+// Name is the name of the subcommand.
+const Name = "config"
+
+// The output format can either be text or JSON, and Markdown or env for
+// the docs.
+const (
+	TextFormat     = "text"
+	JSONFormat     = "json"
+	MarkdownFormat = "markdown"
+	EnvFormat      = "env"
+)
+
+const commands = "print, schema, docs, encrypt, rotate"
+
+// Loader loads the config and records the provenance of its fields, like
+// aconfigo.Loader.
+type Loader interface {
+	Load(dst any) error
+	config.ProvenanceSource
+}
+
+// Command is the config subcommand.
+type Command struct {
+	// Config is a pointer to the config struct to load into.
+	Config any
+
+	// Loader loads Config.
+	Loader Loader
+
+	// EnvPrefix is the env prefix of the app, selecting the env vars of
+	// the age keys, like ORDERS_CONFIG_AGE_KEY.
+	EnvPrefix string
+
+	// In is the input of the command, os.Stdin by default.
+	In io.Reader
+
+	// Out is the output of the command, os.Stdout by default.
+	Out io.Writer
+}
+
+// Handle runs the command if args start with Name and reports whether
+// they did.
+func (c *Command) Handle(args []string) (bool, error) {
+	if len(args) == 0 || args[0] != Name {
+		return false, nil
+	}
+	return true, c.Run(args[1:])
+}
+
+// Run runs the command with args following Name.
+func (c *Command) Run(args []string) error {
+	if len(args) == 0 {
+		return errors.New("config: missing command, want one of: " + commands)
+	}
+
+	switch args[0] {
+	case "print":
+		return c.print(args[1:])
+	case "schema":
+		return c.schema(args[1:])
+	case "docs":
+		return c.docs(args[1:])
+	case "encrypt":
+		return c.encrypt(args[1:])
+	case "rotate":
+		return c.rotate(args[1:])
+	default:
+		return fmt.Errorf("config: unknown command %q, want one of: %s", args[0], commands)
+	}
+}
+
+func (c *Command) print(args []string) error {
+	fs := flag.NewFlagSet(Name+" print", flag.ContinueOnError)
+	fs.SetOutput(c.out())
+	sources := fs.Bool("sources", false, "print the source of every value")
+	format := fs.String("format", TextFormat, "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := c.Loader.Load(c.Config); err != nil {
+		return err
+	}
+	provenance := c.Loader.Provenance()
+
+	switch *format {
+	case TextFormat:
+		return provenance.WriteText(c.out(), *sources)
+	case JSONFormat:
+		enc := json.NewEncoder(c.out())
+		enc.SetIndent("", "  ")
+		if *sources {
+			return enc.Encode(provenance)
+		}
+		return enc.Encode(c.Config)
+	default:
+		return fmt.Errorf("config: unsupported format %q", *format)
+	}
+}
+
+func (c *Command) schema(args []string) error {
+	fs := flag.NewFlagSet(Name+" schema", flag.ContinueOnError)
+	fs.SetOutput(c.out())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	schema, err := config.JSONSchema(c.Config)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(c.out())
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
+
+func (c *Command) docs(args []string) error {
+	fs := flag.NewFlagSet(Name+" docs", flag.ContinueOnError)
+	fs.SetOutput(c.out())
+	format := fs.String("format", MarkdownFormat, "output format: markdown or env")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	namer, _ := c.Loader.(config.FieldNamer)
+	ref, err := config.NewReference(c.Config, namer)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case MarkdownFormat:
+		return ref.WriteMarkdown(c.out())
+	case EnvFormat:
+		return ref.WriteEnv(c.out())
+	default:
+		return fmt.Errorf("config: unsupported format %q", *format)
+	}
+}
+
+func (c *Command) in() io.Reader {
+	if c.In == nil {
+		return os.Stdin
+	}
+	return c.In
+}
+
+func (c *Command) out() io.Writer {
+	if c.Out == nil {
+		return os.Stdout
+	}
+	return c.Out
+}
@@ -0,0 +1,157 @@
+package configcmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/config/configcmd"
+	"github.com/go-toho/toho/contrib/config/aconfigo"
+)
+
+type testConfig struct {
+	Addr  string
+	Token config.Secret
+}
+
+type testLoader struct{}
+
+func (testLoader) Load(dst any) error {
+	cfg := dst.(*testConfig)
+	cfg.Addr = ":8080"
+	cfg.Token = "hunter2"
+	return nil
+}
+
+func (testLoader) Provenance() config.Provenance {
+	return config.Provenance{
+		{Path: "Addr", Value: ":8080", Kind: config.OriginEnv, Name: "APP_ADDR"},
+		{Path: "Token", Value: config.Redacted, Kind: config.OriginDefault},
+	}
+}
+
+func TestHandleIgnoresOtherCommands(t *testing.T) {
+	cmd := &configcmd.Command{Config: &testConfig{}, Loader: testLoader{}}
+
+	handled, err := cmd.Handle([]string{"serve"})
+	if handled || err != nil {
+		t.Fatalf("Handle() = %v, %v, want false, nil", handled, err)
+	}
+}
+
+func TestPrintSources(t *testing.T) {
+	var out bytes.Buffer
+	cmd := &configcmd.Command{Config: &testConfig{}, Loader: testLoader{}, Out: &out}
+
+	handled, err := cmd.Handle([]string{"config", "print", "--sources"})
+	if !handled || err != nil {
+		t.Fatalf("Handle() = %v, %v, want true, nil", handled, err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"Addr", ":8080", "env APP_ADDR", "Token", config.Redacted, "default"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPrintJSONRedactsSecrets(t *testing.T) {
+	var out bytes.Buffer
+	cmd := &configcmd.Command{Config: &testConfig{}, Loader: testLoader{}, Out: &out}
+
+	if err := cmd.Run([]string{"print", "--format", "json"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if strings.Contains(out.String(), "hunter2") {
+		t.Fatalf("output leaks the secret:\n%s", out.String())
+	}
+}
+
+type docsConfig struct {
+	HTTP struct {
+		ReadTimeout string `default:"5s" usage:"read timeout"`
+	}
+}
+
+func TestDocsUsesLoaderNames(t *testing.T) {
+	var out bytes.Buffer
+	loader := aconfigo.NewLoader().WithAppName("orders")
+	cmd := &configcmd.Command{Config: &docsConfig{}, Loader: loader, Out: &out}
+
+	if err := cmd.Run([]string{"docs"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := "| `http.read_timeout` | string | `5s` | `ORDERS_HTTP_READ_TIMEOUT` | `-orders.http.read_timeout` | read timeout |"
+	if !strings.Contains(out.String(), want) {
+		t.Fatalf("output does not contain %q:\n%s", want, out.String())
+	}
+}
+
+func TestSchema(t *testing.T) {
+	var out bytes.Buffer
+	cmd := &configcmd.Command{Config: &docsConfig{}, Loader: testLoader{}, Out: &out}
+
+	if err := cmd.Run([]string{"schema"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for _, want := range []string{config.SchemaDraft, `"read_timeout"`, `"default": "5s"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestEncryptAndRotate(t *testing.T) {
+	oldKey, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.AgeKeyEnv, oldKey.String())
+
+	var out bytes.Buffer
+	cmd := &configcmd.Command{In: strings.NewReader("hunter2\n"), Out: &out}
+	if err := cmd.Run([]string{"encrypt"}); err != nil {
+		t.Fatalf("Run(encrypt) error = %v", err)
+	}
+	encrypted := strings.TrimSpace(out.String())
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("token: "+encrypted+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// both keys decrypt during the rotation
+	t.Setenv(config.AgeKeyEnv, oldKey.String()+"\n"+newKey.String())
+	out.Reset()
+	if err := cmd.Run([]string{"rotate", "--recipient", newKey.Recipient().String(), file}); err != nil {
+		t.Fatalf("Run(rotate) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "1 values rotated") {
+		t.Errorf("rotate output = %q, want 1 values rotated", out.String())
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated := strings.TrimSpace(strings.TrimPrefix(string(data), "token: "))
+	if got, err := config.Decrypt(rotated, newKey); err != nil || got != "hunter2" {
+		t.Fatalf("Decrypt() with the new key = %q, %v, want hunter2", got, err)
+	}
+	if _, err := config.Decrypt(rotated, oldKey); err == nil {
+		t.Fatal("Decrypt() with the old key succeeded after rotation")
+	}
+}
@@ -0,0 +1,176 @@
+package configcmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+
+	"github.com/go-toho/toho/config"
+)
+
+func (c *Command) encrypt(args []string) error {
+	fs := flag.NewFlagSet(Name+" encrypt", flag.ContinueOnError)
+	fs.SetOutput(c.out())
+	recipients := recipientFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var value string
+	switch fs.NArg() {
+	case 0:
+		data, err := io.ReadAll(c.in())
+		if err != nil {
+			return err
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	case 1:
+		value = fs.Arg(0)
+	default:
+		return fmt.Errorf("config: encrypt: want one value, got %d", fs.NArg())
+	}
+
+	to, err := recipients.parse(nil, c.EnvPrefix)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := config.Encrypt(value, to...)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.out(), encrypted)
+	return err
+}
+
+func (c *Command) rotate(args []string) error {
+	fs := flag.NewFlagSet(Name+" rotate", flag.ContinueOnError)
+	fs.SetOutput(c.out())
+	recipients := recipientFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("config: rotate: missing files")
+	}
+
+	identities, err := config.AgeKeys(c.EnvPrefix)
+	if err != nil {
+		return err
+	}
+	to, err := recipients.parse(identities, c.EnvPrefix)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range fs.Args() {
+		n, err := rotateFile(file, identities, to)
+		if err != nil {
+			return fmt.Errorf("config: rotate %s: %w", file, err)
+		}
+		fmt.Fprintf(c.out(), "%s: %d values rotated\n", file, n)
+	}
+	return nil
+}
+
+// rotateFile re-encrypts the encrypted values of file in place.
+func rotateFile(file string, identities []age.Identity, recipients []age.Recipient) (int, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+
+	rotated, n, err := config.Reencrypt(data, identities, recipients...)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+
+	// replace the file at once, a partly written file loses the values
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(rotated); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	return n, os.Rename(tmp.Name(), file)
+}
+
+// recipients are the recipients set by the flags.
+type recipients struct {
+	keys  stringsFlag
+	files stringsFlag
+}
+
+func recipientFlags(fs *flag.FlagSet) *recipients {
+	r := &recipients{}
+	fs.Var(&r.keys, "recipient", "age public key to encrypt for, repeatable")
+	fs.Var(&r.files, "recipients-file", "file of age public keys to encrypt for, repeatable")
+	return r
+}
+
+// parse returns the recipients set by the flags, or else the ones of the
+// identities, loaded with config.AgeKeys of envPrefix if nil.
+func (r *recipients) parse(identities []age.Identity, envPrefix string) ([]age.Recipient, error) {
+	var to []age.Recipient
+	for _, key := range r.keys {
+		recipient, err := age.ParseX25519Recipient(key)
+		if err != nil {
+			return nil, err
+		}
+		to = append(to, recipient)
+	}
+	for _, file := range r.files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := age.ParseRecipients(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		to = append(to, parsed...)
+	}
+	if len(to) > 0 {
+		return to, nil
+	}
+
+	if identities == nil {
+		var err error
+		if identities, err = config.AgeKeys(envPrefix); err != nil {
+			return nil, fmt.Errorf("%w, or set --recipient", err)
+		}
+	}
+	return config.AgeRecipients(identities), nil
+}
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
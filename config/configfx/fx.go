@@ -1,8 +1,9 @@
 package configfx
 
 import (
+	"log/slog"
+	"os"
 	"reflect"
-	"strings"
 
 	"go.uber.org/fx"
 
@@ -10,11 +11,14 @@ import (
 	"github.com/go-toho/toho/pkg/fxtags"
 )
 
-const configStructSuffix = "config"
-
 var Module = fx.Module("config",
 	configStructCheck,
 	ensureConfigOutOption,
+	configValidate,
+	provideReloader,
+	provideValues,
+	invokeSubscribers,
+	invokeCheckKeys,
 )
 
 var (
@@ -27,6 +31,15 @@ var (
 		),
 	)
 
+	configValidate = fx.Invoke(
+		fx.Annotate(
+			func(cfg any) error {
+				return config.Validate(resolvedConfigPointer(cfg))
+			},
+			fx.ParamTags(fxtags.Named(config.NamedConfigPointerOut)),
+		),
+	)
+
 	ensureConfigOutOption = fx.Invoke(
 		fx.Annotate(
 			func(_ any) {},
@@ -39,113 +52,146 @@ func SupplyConfigPointer(c any) fx.Option {
 	var fxopts []fx.Option
 
 	fxopts = append(fxopts, provideConfigPointer(c))
-	fxopts = append(fxopts, structInnerConfigProviders(c)...)
+	fxopts = append(fxopts, sectionProviders(c)...)
 
 	return fx.Options(fxopts...)
 }
 
 func SupplyConfigFile(filename string) fx.Option {
+	return SupplyConfigFiles([]string{filename})
+}
+
+// SupplyConfigFiles supplies config files in increasing precedence, loaded
+// after the ones supplied before, see config.OrderFiles.
+func SupplyConfigFiles(files []string) fx.Option {
+	return SupplyConfigFilesWithPriority(0, files)
+}
+
+// SupplyConfigFilesWithPriority supplies config files in increasing
+// precedence, loaded after the ones of lower priority.
+func SupplyConfigFilesWithPriority(priority int, files []string) fx.Option {
+	supplied := config.NewFiles(priority, files...)
 	return fx.Provide(
 		fx.Annotate(
-			func() string { return filename },
+			func() config.Files { return supplied },
 			fx.ResultTags(fxtags.Group(config.GroupConfigFiles)),
 		),
 	)
 }
 
-func SupplyConfigFiles(files []string) fx.Option {
+// SupplyProfile selects the config profile, instead of the --profile flag
+// or the APP_ENV env var.
+func SupplyProfile(profile string) fx.Option {
 	return fx.Provide(
 		fx.Annotate(
-			func() []string { return files },
-			fx.ResultTags(fxtags.GroupFlatten(config.GroupConfigFiles)),
+			func() string { return profile },
+			fx.ResultTags(fxtags.Named(config.NamedProfile)),
 		),
 	)
 }
 
-func SupplyConfig(cfg any) fx.Option {
+// DetectProfile defaults the config profile to the one selected by the
+// --profile flag or the APP_ENV env var, unless supplied. The tohofx core
+// includes it.
+var DetectProfile = fx.Decorate(
+	fx.Annotate(
+		func(profile string) string {
+			if profile == "" {
+				return config.DetectProfile(os.Args[1:])
+			}
+			return profile
+		},
+		fx.ParamTags(fxtags.NamedOptional(config.NamedProfile)),
+		fx.ResultTags(fxtags.Named(config.NamedProfile)),
+	),
+)
+
+// SupplySearchPaths sets the directories searched for the config files,
+// instead of config.DefaultSearchPaths.
+func SupplySearchPaths(paths ...string) fx.Option {
 	return fx.Provide(
 		fx.Annotate(
-			func() any { return cfg },
-			fx.ResultTags(fxtags.Named(config.NamedConfigPointerOut)),
+			func() []string { return paths },
+			fx.ResultTags(fxtags.Named(config.NamedSearchPaths)),
 		),
 	)
 }
 
-func provideConfigPointer(c any) fx.Option {
+// SupplySearchPolicy selects the config files loaded when found in several
+// search paths, config.FirstMatch by default.
+func SupplySearchPolicy(policy config.SearchPolicy) fx.Option {
 	return fx.Provide(
 		fx.Annotate(
-			func() any { return c },
-			fx.ResultTags(fxtags.Named(config.NamedConfigPointerIn)),
+			func() config.SearchPolicy { return policy },
+			fx.ResultTags(fxtags.Named(config.NamedSearchPolicy)),
 		),
 	)
 }
 
-func structInnerConfigProviders(structure any) []fx.Option {
-	var opts []fx.Option
-	inputType := reflect.TypeOf(structure)
-	if inputType != nil {
-		if inputType.Kind() == reflect.Pointer {
-			if inputType.Elem().Kind() == reflect.Struct {
-				inputValue := reflect.ValueOf(structure)
-				if inputValue.IsNil() {
-					return opts
-				}
-
-				return appendConfigStructProvider(opts, inputValue.Elem(), config.NamedConfigPointerOut)
-			}
-		}
-	}
-	return opts
+func SupplyConfig(cfg any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() any { return cfg },
+			fx.ResultTags(fxtags.Named(config.NamedConfigPointerOut)),
+		),
+	)
 }
 
-func appendConfigStructProvider(opts []fx.Option, s reflect.Value, paramName string) []fx.Option {
-	for i := 0; i < s.NumField(); i++ {
-		field := s.Field(i)
-		fieldType := s.Type().Field(i).Type
-		typeName := fieldType.String()
-
-		if fieldType.Kind() == reflect.Struct {
-			if strings.HasSuffix(strings.ToLower(typeName), configStructSuffix) {
-				if !field.CanInterface() {
-					continue
-				}
+// SupplyLoader loads the config with loader, instead of a config module
+// such as aconfigofx.
+func SupplyLoader(loader config.Loader) fx.Option {
+	fxopts := []fx.Option{
+		fx.Provide(
+			fx.Annotate(
+				func(cfg any) (any, error) {
+					if err := loader.Load(cfg); err != nil {
+						return nil, err
+					}
+					return cfg, nil
+				},
+				fx.ParamTags(fxtags.Named(config.NamedConfigPointerIn)),
+				fx.ResultTags(fxtags.Named(config.NamedConfigPointerOut)),
+			),
+			fx.Annotate(
+				func() config.LoadFunc { return loader.Load },
+				fx.ResultTags(fxtags.Named(config.NamedConfigLoadFunc)),
+			),
+		),
+	}
 
-				opts = append(opts, innerConfigStructProvider(paramName, typeName, i))
-				opts = appendConfigStructProvider(opts, field, typeName)
-			}
-		} else if fieldType.Kind() == reflect.Pointer {
-			if !field.CanInterface() {
-				continue
-			}
-			if !field.IsZero() && field.Elem().Type().Kind() == reflect.Struct {
-				opts = appendConfigStructProvider(opts, field.Elem(), paramName)
-				continue
-			}
-		}
+	if source, ok := loader.(config.ProvenanceSource); ok {
+		fxopts = append(fxopts, fx.Provide(
+			func() config.ProvenanceSource { return source },
+		))
 	}
 
-	return opts
+	return fx.Options(fxopts...)
 }
 
-func innerConfigStructProvider(inName, outName string, fieldIndex int) fx.Option {
+func provideConfigPointer(c any) fx.Option {
 	return fx.Provide(
 		fx.Annotate(
-			func(c any) any {
-				if c == nil {
-					return nil
-				}
-				s := reflect.ValueOf(c)
-				if s.Kind() == reflect.Pointer {
-					if !s.IsZero() && s.Elem().Type().Kind() == reflect.Struct {
-						s = s.Elem()
-					} else {
-						return nil
-					}
-				}
-				return s.Field(fieldIndex).Interface()
-			},
-			fx.ParamTags(fxtags.Named(inName)),
-			fx.ResultTags(fxtags.Named(outName)),
+			func() any { return c },
+			fx.ResultTags(fxtags.Named(config.NamedConfigPointerIn)),
 		),
 	)
 }
+
+// resolvedConfigPointer returns a pointer to the resolved config, which may
+// be supplied by value.
+func resolvedConfigPointer(cfg any) any {
+	if v := reflect.ValueOf(cfg); v.Kind() == reflect.Struct {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface()
+	}
+	return cfg
+}
+
+// loggerOrDefault returns log, or the default logger if none is provided.
+func loggerOrDefault(log *slog.Logger) *slog.Logger {
+	if log == nil {
+		return slog.Default()
+	}
+	return log
+}
@@ -1,11 +1,14 @@
 package configfx
 
 import (
+	"bytes"
+	"log/slog"
 	"strings"
 	"testing"
 
 	"go.uber.org/fx"
 
+	"github.com/go-toho/toho/config"
 	"github.com/go-toho/toho/pkg/fxtags"
 )
 
@@ -202,3 +205,276 @@ func TestSupplyConfigPointerSkipsUnexportedNestedConfigFields(t *testing.T) {
 		t.Fatalf("expected unexported field to be skipped without panic, got: %v", err)
 	}
 }
+
+type ValidatedConfig struct {
+	HTTP ValidatedHTTPConfig
+}
+
+type ValidatedHTTPConfig struct {
+	Addr string `validate:"required"`
+}
+
+func TestModuleValidatesResolvedConfig(t *testing.T) {
+	root := &ValidatedConfig{}
+
+	app := fx.New(
+		fx.NopLogger,
+		SupplyConfigPointer(root),
+		SupplyConfig(root),
+		Module,
+	)
+	err := app.Err()
+	if err == nil {
+		t.Fatal("expected validation error, got nil")
+	}
+	if !strings.Contains(err.Error(), "HTTP.Addr: required") {
+		t.Fatalf("expected HTTP.Addr validation error, got: %v", err)
+	}
+}
+
+type ReplicaSettings struct {
+	DSN string
+}
+
+type TaggedRootConfig struct {
+	Primary  *DatabaseConfig           `toho:"name=db.primary"`
+	Replica  ReplicaSettings           `toho:"provide"`
+	Shards   map[string]DatabaseConfig `toho:"name=db.shards"`
+	Replicas []ReplicaSettings         `toho:"provide"`
+	Ignored  HTTPConfig                `toho:"-"`
+	Caches   map[string]ReplicaSettings
+}
+
+func TestSupplyConfigPointerExposesTaggedSections(t *testing.T) {
+	// sections are resolved from the loaded config, not the supplied one
+	loaded := &TaggedRootConfig{
+		Primary:  &DatabaseConfig{DSN: "postgres://primary"},
+		Replica:  ReplicaSettings{DSN: "postgres://replica"},
+		Shards:   map[string]DatabaseConfig{"eu": {DSN: "postgres://eu"}},
+		Replicas: []ReplicaSettings{{DSN: "postgres://r1"}},
+	}
+
+	var (
+		primary  any
+		typed    DatabaseConfig
+		replica  ReplicaSettings
+		shards   map[string]DatabaseConfig
+		replicas []ReplicaSettings
+	)
+	app := fx.New(
+		fx.NopLogger,
+		SupplyConfigPointer(&TaggedRootConfig{}),
+		SupplyConfig(loaded),
+		fx.Populate(
+			fx.Annotate(&primary, fx.ParamTags(fxtags.Named("db.primary"))),
+			fx.Annotate(&typed, fx.ParamTags(fxtags.Named("db.primary"))),
+			&replica,
+			fx.Annotate(&shards, fx.ParamTags(fxtags.Named("db.shards"))),
+			&replicas,
+		),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("expected app to build, got error: %v", err)
+	}
+
+	if got, ok := primary.(DatabaseConfig); !ok || got.DSN != "postgres://primary" {
+		t.Fatalf("expected named primary DatabaseConfig, got %#v", primary)
+	}
+	if typed.DSN != "postgres://primary" {
+		t.Fatalf("expected typed primary DSN, got %q", typed.DSN)
+	}
+	if replica.DSN != "postgres://replica" {
+		t.Fatalf("expected replica DSN, got %q", replica.DSN)
+	}
+	if shards["eu"].DSN != "postgres://eu" {
+		t.Fatalf("expected eu shard, got %v", shards)
+	}
+	if len(replicas) != 1 || replicas[0].DSN != "postgres://r1" {
+		t.Fatalf("expected replicas, got %v", replicas)
+	}
+}
+
+func TestSupplyConfigPointerProvidesNilPointerSectionAsNil(t *testing.T) {
+	var primary any = "unset"
+	app := fx.New(
+		fx.NopLogger,
+		SupplyConfigPointer(&TaggedRootConfig{}),
+		SupplyConfig(&TaggedRootConfig{}),
+		fx.Populate(fx.Annotate(&primary, fx.ParamTags(fxtags.Named("db.primary")))),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("expected app to build, got error: %v", err)
+	}
+	if primary != nil {
+		t.Fatalf("expected nil primary section, got %#v", primary)
+	}
+}
+
+func TestSupplyConfigPointerSkipsIgnoredSections(t *testing.T) {
+	var httpConfig any
+	app := fx.New(
+		fx.NopLogger,
+		SupplyConfigPointer(&TaggedRootConfig{}),
+		SupplyConfig(&TaggedRootConfig{}),
+		fx.Populate(fx.Annotate(&httpConfig, fx.ParamTags(fxtags.Named(httpConfigName)))),
+	)
+	if err := app.Err(); err == nil {
+		t.Fatal("expected section tagged toho:\"-\" not to be provided")
+	}
+}
+
+type ServiceSettings struct {
+	HTTP  HTTPConfig
+	Store ReplicaSettings
+}
+
+type DuplicateSectionsConfig struct {
+	Public  ServiceSettings
+	Admin   ServiceSettings
+	Primary ReplicaSettings `toho:"provide"`
+}
+
+func TestSupplyConfigPointerProvidesDuplicateSectionsOnce(t *testing.T) {
+	loaded := &DuplicateSectionsConfig{
+		Public:  ServiceSettings{HTTP: HTTPConfig{Addr: ":8080"}, Store: ReplicaSettings{DSN: "postgres://public"}},
+		Admin:   ServiceSettings{HTTP: HTTPConfig{Addr: ":9090"}, Store: ReplicaSettings{DSN: "postgres://admin"}},
+		Primary: ReplicaSettings{DSN: "postgres://primary"},
+	}
+
+	var (
+		httpConfig any
+		replica    ReplicaSettings
+	)
+	app := fx.New(
+		fx.NopLogger,
+		SupplyConfigPointer(&DuplicateSectionsConfig{}),
+		SupplyConfig(loaded),
+		fx.Populate(
+			fx.Annotate(&httpConfig, fx.ParamTags(fxtags.Named(httpConfigName))),
+			&replica,
+		),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("expected app to build, got error: %v", err)
+	}
+
+	if got, ok := httpConfig.(HTTPConfig); !ok || got.Addr != ":8080" {
+		t.Errorf("expected the first declared HTTPConfig, got %#v", httpConfig)
+	}
+	if replica.DSN != "postgres://primary" {
+		t.Errorf("expected the tagged ReplicaSettings, got %q", replica.DSN)
+	}
+}
+
+type TaggedServiceSettings struct {
+	Store ReplicaSettings `toho:"provide"`
+}
+
+type CollidingSectionsConfig struct {
+	Public  TaggedServiceSettings
+	Primary ReplicaSettings `toho:"provide"`
+}
+
+func TestSupplyConfigPointerRejectsCollidingTaggedSections(t *testing.T) {
+	app := fx.New(
+		fx.NopLogger,
+		SupplyConfigPointer(&CollidingSectionsConfig{}),
+		SupplyConfig(&CollidingSectionsConfig{}),
+	)
+
+	err := app.Err()
+	if err == nil {
+		t.Fatal("expected an error for the colliding tags, got nil")
+	}
+	if want := `config sections Primary and Public.Store both expose the name "configfx.ReplicaSettings"`; !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected error %q, got: %v", want, err)
+	}
+}
+
+func TestSupplyConfigFilesKeepsSuppliedOrder(t *testing.T) {
+	options := fx.Options(
+		SupplyConfigFiles([]string{"base.yaml", "app.yaml"}),
+		SupplyConfigFile("local.yaml"),
+		SupplyConfigFilesWithPriority(-1, []string{"defaults.yaml"}),
+	)
+
+	// group values come in random order
+	for i := 0; i < 10; i++ {
+		var files []string
+		app := fx.New(
+			fx.NopLogger,
+			options,
+			fx.Invoke(
+				fx.Annotate(
+					func(supplied []config.Files) { files = config.OrderFiles(supplied) },
+					fx.ParamTags(fxtags.Group(config.GroupConfigFiles)),
+				),
+			),
+		)
+		if err := app.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := strings.Join(files, ","); got != "defaults.yaml,base.yaml,app.yaml,local.yaml" {
+			t.Fatalf("files = %s, want the supplied order", got)
+		}
+	}
+}
+
+func TestDetectProfile(t *testing.T) {
+	t.Setenv(config.ProfileEnv, config.ProfileStaging)
+
+	tests := []struct {
+		name    string
+		options []fx.Option
+		want    string
+	}{
+		{name: "detected", want: config.ProfileStaging},
+		{name: "supplied", options: []fx.Option{SupplyProfile(config.ProfileProd)}, want: config.ProfileProd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var profile string
+			app := fx.New(
+				fx.NopLogger,
+				fx.Options(tt.options...),
+				DetectProfile,
+				fx.Module("child",
+					fx.Invoke(
+						fx.Annotate(
+							func(p string) { profile = p },
+							fx.ParamTags(fxtags.NamedOptional(config.NamedProfile)),
+						),
+					),
+				),
+			)
+			if err := app.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if profile != tt.want {
+				t.Fatalf("profile = %q, want %q", profile, tt.want)
+			}
+		})
+	}
+}
+
+func TestModuleReportsKeysThroughAppLogger(t *testing.T) {
+	root := &RootConfig{}
+
+	var buf bytes.Buffer
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.NewTextHandler(&buf, nil))),
+		SupplyConfigPointer(root),
+		SupplyConfig(root),
+		SupplyKey(config.KeyFor[DatabaseConfig]("db")),
+		Module,
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("expected app to build, got error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `msg="config section unused, no module owns its key" section="database (configfx.DatabaseConfig)"`) {
+		t.Fatalf("app logger output = %q, want the unused section", buf.String())
+	}
+}
@@ -0,0 +1,45 @@
+package configfx
+
+import (
+	"log/slog"
+
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/pkg/fxtags"
+)
+
+var invokeCheckKeys = fx.Invoke(
+	fx.Annotate(
+		func(cfg any, keys []config.Key, log *slog.Logger) {
+			log = loggerOrDefault(log)
+			report := config.CheckKeys(resolvedConfigPointer(cfg), keys)
+			for _, path := range report.Unused {
+				log.Warn("config section unused, no module owns its key", slog.String("section", path))
+			}
+			for _, path := range report.Unknown {
+				log.Warn("config section unknown to the module owning its key", slog.String("section", path))
+			}
+			for _, key := range report.Missing {
+				log.Debug("config section missing, module defaults apply", slog.String("key", key))
+			}
+		},
+		fx.ParamTags(
+			fxtags.Named(config.NamedConfigPointerOut),
+			fxtags.Group(config.GroupConfigKeys),
+			fxtags.Optional,
+		),
+	),
+)
+
+// SupplyKey declares the key of the section owned by a module, which the
+// module binds from the root config with config.BindSection. The sections
+// the keys do not bind are reported on start.
+func SupplyKey(key config.Key) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() config.Key { return key },
+			fx.ResultTags(fxtags.Group(config.GroupConfigKeys)),
+		),
+	)
+}
@@ -0,0 +1,122 @@
+package configfx
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/pkg/fxtags"
+)
+
+var (
+	provideReloader = fx.Provide(
+		fx.Annotate(
+			newReloader,
+			fx.ParamTags(
+				fxtags.Named(config.NamedConfigPointerOut),
+				fxtags.NamedOptional(config.NamedConfigLoadFunc),
+				fxtags.Optional,
+			),
+		),
+	)
+
+	invokeSubscribers = fx.Invoke(
+		fx.Annotate(
+			func(reloader *config.Reloader, subscribers []config.Subscriber) {
+				for _, s := range subscribers {
+					if s != nil {
+						reloader.Subscribe(s)
+					}
+				}
+			},
+			fx.ParamTags(
+				fxtags.Empty,
+				fxtags.Group(config.GroupConfigSubscribers),
+			),
+		),
+	)
+)
+
+// ReloadOnSignal reloads the config on SIGHUP while the application runs.
+var ReloadOnSignal = fx.Invoke(func(lifecycle fx.Lifecycle, reloader *config.Reloader) {
+	appendReloadHook(lifecycle, func(ctx context.Context) {
+		reloader.ReloadOnSignal(ctx)
+	})
+})
+
+// ReloadOnFileChange reloads the config whenever one of the config files
+// changes while the application runs. Files are checked every interval.
+// The files are those of the loader if it is a config.FileLister, like
+// found in the search paths, or else the supplied ones.
+func ReloadOnFileChange(interval time.Duration) fx.Option {
+	return fx.Invoke(
+		fx.Annotate(
+			func(lifecycle fx.Lifecycle, reloader *config.Reloader, supplied []config.Files, paths []string, lister config.FileLister) {
+				files := config.OrderFiles(append(supplied, config.Files{Paths: paths}))
+				if lister != nil {
+					files = lister.ConfigFiles()
+				}
+				appendReloadHook(lifecycle, func(ctx context.Context) {
+					reloader.ReloadOnFileChange(ctx, files, interval)
+				})
+			},
+			fx.ParamTags(
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Group(config.GroupConfigFiles),
+				fxtags.Group(config.GroupConfigFiles),
+				fxtags.Optional,
+			),
+		),
+	)
+}
+
+// Watch subscribes fn to the changes of the config sections of type T.
+func Watch[T any](fn func(old, new T)) fx.Option {
+	return SupplySubscriber(config.Watch(fn))
+}
+
+func SupplySubscriber(s config.Subscriber) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() config.Subscriber { return s },
+			fx.ResultTags(fxtags.Group(config.GroupConfigSubscribers)),
+		),
+	)
+}
+
+func SupplyLoadFunc(fn config.LoadFunc) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() config.LoadFunc { return fn },
+			fx.ResultTags(fxtags.Named(config.NamedConfigLoadFunc)),
+		),
+	)
+}
+
+func newReloader(cfg any, load config.LoadFunc, log *slog.Logger) (*config.Reloader, error) {
+	log = loggerOrDefault(log)
+	return config.NewReloader(resolvedConfigPointer(cfg), load,
+		config.WithErrorHandler(func(err error) {
+			log.Error("config reload failed", slog.Any("error", err))
+		}),
+	)
+}
+
+func appendReloadHook(lifecycle fx.Lifecycle, run func(context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
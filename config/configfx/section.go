@@ -0,0 +1,275 @@
+package configfx
+
+import (
+	"cmp"
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/pkg/fxtags"
+)
+
+// configStructSuffix marks the section types exposed without a tag.
+const configStructSuffix = "config"
+
+// tohoTag controls how a section field is exposed:
+//
+//	toho:"provide"       also provides the section as its concrete type
+//	toho:"name=db.main"  names the section db.main, as any and as its type
+//	toho:"-"             neither exposes the section nor its fields
+//
+// Untagged struct sections, and pointers to them, whose type name ends in
+// "config" are exposed as any, named by their type like "logger.Config".
+// Maps and slices of sections are only exposed when tagged. The sections
+// of nested sections are exposed too. Tags exposing a name or type
+// exposed by another tag are an error, while an untagged section is
+// skipped when a tagged, shallower or earlier section exposes its name.
+const tohoTag = "toho"
+
+type sectionTag struct {
+	skip    bool
+	provide bool
+	name    string
+}
+
+func parseSectionTag(tag string) sectionTag {
+	var st sectionTag
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		switch {
+		case opt == "-":
+			st.skip = true
+		case opt == "provide":
+			st.provide = true
+		case strings.HasPrefix(opt, "name="):
+			st.name = strings.TrimPrefix(opt, "name=")
+		}
+	}
+	return st
+}
+
+// sectionProviders returns the providers of the sections of the config
+// structure, extracted from the resolved config. A name or type exposed by
+// several tagged sections is an error. Otherwise it is provided once, for
+// the tagged section, or else the shallowest one, the first declared among
+// them.
+func sectionProviders(structure any) []fx.Option {
+	typ := reflect.TypeOf(structure)
+	if typ == nil || typ.Kind() != reflect.Pointer || typ.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	providers := appendSectionProviders(nil, typ.Elem(), nil, map[reflect.Type]bool{typ.Elem(): true})
+	slices.SortStableFunc(providers, func(a, b sectionProvider) int {
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.depth(), b.depth())
+	})
+
+	var opts []fx.Option
+	provided := map[sectionKey]sectionProvider{}
+	for _, p := range providers {
+		first, ok := provided[p.key]
+		switch {
+		case !ok:
+			provided[p.key] = p
+			opts = append(opts, p.opt)
+		case p.tagged:
+			return []fx.Option{fx.Error(fmt.Errorf("config sections %s and %s both expose %s",
+				fieldPath(typ.Elem(), first.index), fieldPath(typ.Elem(), p.index), p.key))}
+		}
+	}
+	return opts
+}
+
+// sectionProvider provides the section at the field index as key, tagged
+// if exposed by a toho tag.
+type sectionProvider struct {
+	key    sectionKey
+	index  []int
+	tagged bool
+	opt    fx.Option
+}
+
+func (p sectionProvider) depth() int {
+	return len(p.index)
+}
+
+// sectionKey is the name and type a section is provided as, the type being
+// nil for any.
+type sectionKey struct {
+	name string
+	typ  reflect.Type
+}
+
+func (k sectionKey) String() string {
+	switch {
+	case k.typ == nil:
+		return fmt.Sprintf("the name %q", k.name)
+	case k.name == "":
+		return "the type " + k.typ.String()
+	}
+	return fmt.Sprintf("the type %s named %q", k.typ, k.name)
+}
+
+// fieldPath returns the dot separated names of the fields at index in typ.
+func fieldPath(typ reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, fieldIndex := range index {
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		field := typ.Field(fieldIndex)
+		names[i] = field.Name
+		typ = field.Type
+	}
+	return strings.Join(names, ".")
+}
+
+func appendSectionProviders(providers []sectionProvider, typ reflect.Type, index []int, parents map[reflect.Type]bool) []sectionProvider {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := parseSectionTag(field.Tag.Get(tohoTag))
+		if tag.skip {
+			continue
+		}
+
+		fieldIndex := append(index[:len(index):len(index)], i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		switch {
+		case isSectionType(fieldType):
+			name := tag.name
+			if name == "" && (tag.provide || hasConfigSuffix(fieldType)) {
+				name = fieldType.String()
+			}
+			if name != "" {
+				providers = append(providers, namedSectionProvider(name, fieldIndex, tag.provide || tag.name != ""))
+			}
+			providers = appendTypedSectionProviders(providers, fieldType, tag, fieldIndex)
+
+			if !parents[fieldType] {
+				parents[fieldType] = true
+				providers = appendSectionProviders(providers, fieldType, fieldIndex, parents)
+				delete(parents, fieldType)
+			}
+		case fieldType.Kind() == reflect.Map || fieldType.Kind() == reflect.Slice:
+			if tag.name != "" {
+				providers = append(providers, namedSectionProvider(tag.name, fieldIndex, true))
+			}
+			providers = appendTypedSectionProviders(providers, fieldType, tag, fieldIndex)
+		}
+	}
+
+	return providers
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func isSectionType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && !reflect.PointerTo(typ).Implements(textUnmarshalerType)
+}
+
+func hasConfigSuffix(typ reflect.Type) bool {
+	return strings.HasSuffix(strings.ToLower(typ.String()), configStructSuffix)
+}
+
+// namedSectionProvider provides the section at index as any, or nil if it
+// is behind a nil pointer.
+func namedSectionProvider(name string, index []int, tagged bool) sectionProvider {
+	opt := fx.Provide(
+		fx.Annotate(
+			func(c any) any {
+				if v, ok := sectionValue(c, index); ok {
+					return v.Interface()
+				}
+				return nil
+			},
+			fx.ParamTags(fxtags.Named(config.NamedConfigPointerOut)),
+			fx.ResultTags(fxtags.Named(name)),
+		),
+	)
+	return sectionProvider{key: sectionKey{name: name}, index: index, tagged: tagged, opt: opt}
+}
+
+// appendTypedSectionProviders provides the section at index as its type,
+// the zero value if it is behind a nil pointer.
+func appendTypedSectionProviders(providers []sectionProvider, typ reflect.Type, tag sectionTag, index []int) []sectionProvider {
+	if tag.provide {
+		providers = append(providers, typedSectionProvider(typ, "", index))
+	}
+	if tag.name != "" {
+		providers = append(providers, typedSectionProvider(typ, tag.name, index))
+	}
+	return providers
+}
+
+func typedSectionProvider(typ reflect.Type, name string, index []int) sectionProvider {
+	resultTag := fxtags.Empty
+	if name != "" {
+		resultTag = fxtags.Named(name)
+	}
+
+	fnType := reflect.FuncOf([]reflect.Type{reflect.TypeFor[any]()}, []reflect.Type{typ}, false)
+	fn := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		v, ok := sectionValue(args[0].Interface(), index)
+		if !ok {
+			return []reflect.Value{reflect.Zero(typ)}
+		}
+		return []reflect.Value{v}
+	})
+
+	opt := fx.Provide(
+		fx.Annotate(
+			fn.Interface(),
+			fx.ParamTags(fxtags.Named(config.NamedConfigPointerOut)),
+			fx.ResultTags(resultTag),
+		),
+	)
+	return sectionProvider{key: sectionKey{name: name, typ: typ}, index: index, tagged: true, opt: opt}
+}
+
+// sectionValue returns the section of the config c at the field index,
+// dereferencing pointers to structs on the way.
+func sectionValue(c any, index []int) (reflect.Value, bool) {
+	v, ok := derefStruct(reflect.ValueOf(c))
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	for _, i := range index {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		if v, ok = derefStruct(v.Field(i)); !ok {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+func derefStruct(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Struct {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
@@ -0,0 +1,47 @@
+package configfx
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/config"
+)
+
+// SupplyValue provides value, a *config.Value of the root config type,
+// holding the resolved config and swapped by reloads.
+func SupplyValue(value config.Subscriber) fx.Option {
+	return fx.Options(
+		fx.Supply(value),
+		fx.Invoke(func(reloader *config.Reloader) error {
+			current := reflect.ValueOf(reloader.Current()).Elem()
+			if current.Type() != value.Type() {
+				return fmt.Errorf("config value type %s does not match %s", value.Type(), current.Type())
+			}
+			value.Notify(nil, current.Interface())
+			reloader.Subscribe(value)
+			return nil
+		}),
+	)
+}
+
+// ProvideValue provides the *config.Value[T] of the provided
+// *config.Values, holding the section of type T of the resolved config.
+func ProvideValue[T any]() fx.Option {
+	return fx.Provide(config.ValueOf[T])
+}
+
+// provideValues provides the *config.Values of the root config and of
+// every section, swapped by reloads. config.ValueOf returns the one of a
+// section type.
+var provideValues = fx.Provide(
+	func(reloader *config.Reloader) (*config.Values, error) {
+		values, err := config.NewValues(reloader.Current())
+		if err != nil {
+			return nil, err
+		}
+		reloader.Subscribe(values)
+		return values, nil
+	},
+)
@@ -0,0 +1,90 @@
+package configfx
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/config"
+)
+
+func TestValuesFollowReloads(t *testing.T) {
+	root := &RootConfig{HTTP: HTTPConfig{Addr: ":8080"}}
+	src := &RootConfig{HTTP: HTTPConfig{Addr: ":9090"}}
+
+	var (
+		reloader  *config.Reloader
+		rootValue *config.Value[RootConfig]
+		http      *config.Value[HTTPConfig]
+	)
+	app := fx.New(
+		fx.NopLogger,
+		SupplyConfigPointer(root),
+		SupplyConfig(root),
+		Module,
+		SupplyLoadFunc(func(dst any) error {
+			*dst.(*RootConfig) = *src
+			return nil
+		}),
+		SupplyValue(config.NewValue(RootConfig{})),
+		ProvideValue[HTTPConfig](),
+		fx.Populate(&reloader, &rootValue, &http),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("expected app to build, got error: %v", err)
+	}
+
+	if got := rootValue.Load().HTTP.Addr; got != ":8080" {
+		t.Fatalf("root Load().HTTP.Addr = %q before reload, want :8080", got)
+	}
+	if got := http.Load().Addr; got != ":8080" {
+		t.Fatalf("section Load().Addr = %q before reload, want :8080", got)
+	}
+
+	if err := reloader.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if got := rootValue.Load().HTTP.Addr; got != ":9090" {
+		t.Errorf("root Load().HTTP.Addr = %q, want :9090", got)
+	}
+	if got := http.Load().Addr; got != ":9090" {
+		t.Errorf("section Load().Addr = %q, want :9090", got)
+	}
+}
+
+func TestValuesProvidedForEverySection(t *testing.T) {
+	root := &RootConfig{HTTP: HTTPConfig{Addr: ":8080"}}
+	src := &RootConfig{HTTP: HTTPConfig{Addr: ":9090"}}
+
+	var (
+		reloader *config.Reloader
+		values   *config.Values
+	)
+	app := fx.New(
+		fx.NopLogger,
+		SupplyConfigPointer(root),
+		SupplyConfig(root),
+		Module,
+		SupplyLoadFunc(func(dst any) error {
+			*dst.(*RootConfig) = *src
+			return nil
+		}),
+		fx.Populate(&reloader, &values),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("expected app to build, got error: %v", err)
+	}
+
+	http, err := config.ValueOf[HTTPConfig](values)
+	if err != nil {
+		t.Fatalf("ValueOf() error = %v", err)
+	}
+	if err := reloader.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := http.Load().Addr; got != ":9090" {
+		t.Errorf("section Load().Addr = %q, want :9090", got)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Decoder decodes the content of a config file into values keyed by field
+// name, see Source.
+type Decoder interface {
+	Decode(data []byte) (map[string]any, error)
+}
+
+// DecoderFunc is a function implementing Decoder.
+type DecoderFunc func(data []byte) (map[string]any, error)
+
+func (f DecoderFunc) Decode(data []byte) (map[string]any, error) {
+	return f(data)
+}
+
+// Built-in decoders.
+var (
+	JSONDecoder Decoder = DecoderFunc(func(data []byte) (map[string]any, error) {
+		values := make(map[string]any)
+		if len(data) == 0 {
+			return values, nil
+		}
+		err := json.Unmarshal(data, &values)
+		return values, err
+	})
+
+	YAMLDecoder Decoder = DecoderFunc(func(data []byte) (map[string]any, error) {
+		values := make(map[string]any)
+		err := yaml.Unmarshal(data, &values)
+		return values, err
+	})
+
+	TOMLDecoder Decoder = DecoderFunc(func(data []byte) (map[string]any, error) {
+		values := make(map[string]any)
+		err := toml.Unmarshal(data, &values)
+		return values, err
+	})
+)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		".json": JSONDecoder,
+		".yaml": YAMLDecoder,
+		".yml":  YAMLDecoder,
+		".toml": TOMLDecoder,
+	}
+)
+
+// RegisterDecoder registers decoder for the files with extension ext,
+// like ".hcl".
+func RegisterDecoder(ext string, decoder Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	decoders[strings.ToLower(ext)] = decoder
+}
+
+// DecoderFor returns the decoder registered for the extension of path.
+func DecoderFor(path string) (Decoder, error) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if decoder, ok := decoders[ext]; ok {
+		return decoder, nil
+	}
+	return nil, fmt.Errorf("config: no decoder for %q files, like %s", ext, path)
+}
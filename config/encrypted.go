@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+)
+
+// Encrypted values of the form ENC[age:<base64>] hold a value encrypted
+// with age. ResolveSecrets decrypts them into Secret fields with the keys
+// of AgeKeys.
+const (
+	EncryptedPrefix = "ENC[age:"
+	EncryptedSuffix = "]"
+)
+
+// AgeKeyEnv holds the age identities decrypting encrypted values, and
+// AgeKeyFileEnv the path of a file holding them, like age-keygen writes.
+// Both follow the env prefix of the app, like ORDERS_CONFIG_AGE_KEY.
+const (
+	AgeKeyEnv     = "CONFIG_AGE_KEY"
+	AgeKeyFileEnv = "CONFIG_AGE_KEY_FILE"
+)
+
+var encryptedPattern = regexp.MustCompile(regexp.QuoteMeta(EncryptedPrefix) + `[A-Za-z0-9+/=]*` + regexp.QuoteMeta(EncryptedSuffix))
+
+// IsEncrypted reports whether s is an encrypted value.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, EncryptedPrefix) && strings.HasSuffix(s, EncryptedSuffix)
+}
+
+// Encrypt returns plaintext encrypted for the recipients, as an encrypted
+// value.
+func Encrypt(plaintext string, recipients ...age.Recipient) (string, error) {
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("config: encrypt: no recipient")
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return "", fmt.Errorf("config: encrypt: %w", err)
+	}
+	if _, err := io.WriteString(w, plaintext); err != nil {
+		return "", fmt.Errorf("config: encrypt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("config: encrypt: %w", err)
+	}
+
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()) + EncryptedSuffix, nil
+}
+
+// Decrypt returns the plaintext of the encrypted value with one of the
+// identities.
+func Decrypt(value string, identities ...age.Identity) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("config: decrypt: not an encrypted value")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, EncryptedPrefix), EncryptedSuffix))
+	if err != nil {
+		return "", fmt.Errorf("config: decrypt: %w", err)
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return "", fmt.Errorf("config: decrypt: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("config: decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// Reencrypt returns data with every encrypted value it holds decrypted
+// with the identities and encrypted again for the recipients, and the
+// number of values.
+func Reencrypt(data []byte, identities []age.Identity, recipients ...age.Recipient) ([]byte, int, error) {
+	var (
+		n       int
+		lastErr error
+	)
+	out := encryptedPattern.ReplaceAllFunc(data, func(value []byte) []byte {
+		if lastErr != nil {
+			return value
+		}
+
+		plaintext, err := Decrypt(string(value), identities...)
+		if err != nil {
+			lastErr = err
+			return value
+		}
+		encrypted, err := Encrypt(plaintext, recipients...)
+		if err != nil {
+			lastErr = err
+			return value
+		}
+
+		n++
+		return []byte(encrypted)
+	})
+	if lastErr != nil {
+		return nil, 0, lastErr
+	}
+	return out, n, nil
+}
+
+// AgeKeyEnvs returns the names of the AgeKeyEnv and AgeKeyFileEnv env vars
+// of the apps with envPrefix.
+func AgeKeyEnvs(envPrefix string) (keyEnv, fileEnv string) {
+	return joinKeyPrefix(envPrefix, "_", AgeKeyEnv), joinKeyPrefix(envPrefix, "_", AgeKeyFileEnv)
+}
+
+// AgeKeys returns the identities held by the AgeKeyEnv env var of the apps
+// with envPrefix, or else by the file at their AgeKeyFileEnv.
+func AgeKeys(envPrefix string) ([]age.Identity, error) {
+	keyEnv, fileEnv := AgeKeyEnvs(envPrefix)
+
+	if keys, ok := os.LookupEnv(keyEnv); ok && keys != "" {
+		identities, err := age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", keyEnv, err)
+		}
+		return identities, nil
+	}
+
+	if path, ok := os.LookupEnv(fileEnv); ok && path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", fileEnv, err)
+		}
+		defer f.Close()
+
+		identities, err := age.ParseIdentities(f)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", fileEnv, err)
+		}
+		return identities, nil
+	}
+
+	return nil, fmt.Errorf("config: no age key, set %s or %s", keyEnv, fileEnv)
+}
+
+// AgeRecipients returns the recipients of the X25519 identities, which
+// encrypt values the identities decrypt.
+func AgeRecipients(identities []age.Identity) []age.Recipient {
+	var recipients []age.Recipient
+	for _, identity := range identities {
+		if x, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, x.Recipient())
+		}
+	}
+	return recipients
+}
+
+// decrypter decrypts the encrypted values of a config, loading the keys
+// of the apps with envPrefix on the first one.
+type decrypter struct {
+	envPrefix string
+
+	once       sync.Once
+	identities []age.Identity
+	err        error
+}
+
+func (d *decrypter) decrypt(value string) (string, error) {
+	d.once.Do(func() {
+		d.identities, d.err = AgeKeys(d.envPrefix)
+	})
+	if d.err != nil {
+		return "", d.err
+	}
+	return Decrypt(value, d.identities...)
+}
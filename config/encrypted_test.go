@@ -0,0 +1,133 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+
+	"github.com/go-toho/toho/config"
+)
+
+type encryptedConfig struct {
+	DSN      string
+	Password config.Secret
+	Tokens   map[string]config.Secret
+}
+
+func newAgeKey(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identity
+}
+
+func encrypt(t *testing.T, plaintext string, recipients ...age.Recipient) string {
+	t.Helper()
+	value, err := config.Encrypt(plaintext, recipients...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestResolveSecretsDecryptsValues(t *testing.T) {
+	key := newAgeKey(t)
+	t.Setenv(config.AgeKeyEnv, key.String())
+
+	cfg := &encryptedConfig{
+		DSN:      "postgres://db",
+		Password: config.Secret(encrypt(t, secretValue, key.Recipient())),
+		Tokens:   map[string]config.Secret{"api": config.Secret(encrypt(t, "env://TOHO_TEST_TOKEN", key.Recipient()))},
+	}
+	t.Setenv("TOHO_TEST_TOKEN", "token-value")
+
+	if err := config.ResolveSecrets(cfg); err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+
+	if cfg.DSN != "postgres://db" {
+		t.Errorf("DSN = %q, want postgres://db", cfg.DSN)
+	}
+	if got := cfg.Password.Value(); got != secretValue {
+		t.Errorf("Password = %q, want %q", got, secretValue)
+	}
+	if got := cfg.Tokens["api"].Value(); got != "token-value" {
+		t.Errorf("Tokens[api] = %q, want the decrypted reference resolved", got)
+	}
+}
+
+func TestResolveSecretsRejectsEncryptedStrings(t *testing.T) {
+	key := newAgeKey(t)
+	t.Setenv(config.AgeKeyEnv, key.String())
+
+	encrypted := encrypt(t, "postgres://db", key.Recipient())
+	cfg := &encryptedConfig{DSN: encrypted}
+
+	err := config.ResolveSecrets(cfg)
+	if err == nil || !strings.Contains(err.Error(), "DSN") || !strings.Contains(err.Error(), "config.Secret") {
+		t.Fatalf("ResolveSecrets() error = %v, want DSN encrypted string error", err)
+	}
+	if cfg.DSN != encrypted {
+		t.Errorf("DSN = %q, want it left encrypted", cfg.DSN)
+	}
+}
+
+func TestResolveSecretsWithAgeKeyEnvPrefix(t *testing.T) {
+	key, other := newAgeKey(t), newAgeKey(t)
+	keyEnv, fileEnv := config.AgeKeyEnvs("ORDERS")
+	if keyEnv != "ORDERS_CONFIG_AGE_KEY" || fileEnv != "ORDERS_CONFIG_AGE_KEY_FILE" {
+		t.Fatalf("AgeKeyEnvs() = %s, %s, want ORDERS_ prefixed names", keyEnv, fileEnv)
+	}
+	t.Setenv(keyEnv, key.String())
+	// the unprefixed key belongs to another app
+	t.Setenv(config.AgeKeyEnv, other.String())
+
+	cfg := &encryptedConfig{Password: config.Secret(encrypt(t, secretValue, key.Recipient()))}
+	if err := config.ResolveSecrets(cfg, config.WithAgeKeyEnvPrefix("ORDERS")); err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+	if got := cfg.Password.Value(); got != secretValue {
+		t.Errorf("Password = %q, want %q", got, secretValue)
+	}
+}
+
+func TestResolveSecretsRequiresAgeKey(t *testing.T) {
+	key := newAgeKey(t)
+	t.Setenv(config.AgeKeyEnv, "")
+	t.Setenv(config.AgeKeyFileEnv, "")
+
+	cfg := &encryptedConfig{Password: config.Secret(encrypt(t, secretValue, key.Recipient()))}
+
+	err := config.ResolveSecrets(cfg)
+	if err == nil || !strings.Contains(err.Error(), "Password") || !strings.Contains(err.Error(), config.AgeKeyEnv) {
+		t.Fatalf("ResolveSecrets() error = %v, want Password missing key error", err)
+	}
+}
+
+func TestReencrypt(t *testing.T) {
+	oldKey, newKey := newAgeKey(t), newAgeKey(t)
+
+	data := []byte("password: " + encrypt(t, secretValue, oldKey.Recipient()) + "\nuser: toho\n")
+
+	rotated, n, err := config.Reencrypt(data, []age.Identity{oldKey}, newKey.Recipient())
+	if err != nil {
+		t.Fatalf("Reencrypt() error = %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Reencrypt() rotated %d values, want 1", n)
+	}
+
+	value, _, _ := strings.Cut(strings.TrimPrefix(string(rotated), "password: "), "\n")
+	if _, err := config.Decrypt(value, oldKey); err == nil {
+		t.Error("Decrypt() with the old key succeeded after rotation")
+	}
+	if got, err := config.Decrypt(value, newKey); err != nil || got != secretValue {
+		t.Errorf("Decrypt() with the new key = %q, %v, want %q", got, err, secretValue)
+	}
+	if !strings.HasSuffix(string(rotated), "\nuser: toho\n") {
+		t.Errorf("Reencrypt() changed the rest of the file:\n%s", rotated)
+	}
+}
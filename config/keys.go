@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Key declares the section of the root config owned by a module: the
+// section at the dot separated Key, of type Type or a pointer to it.
+type Key struct {
+	Key  string
+	Type reflect.Type
+}
+
+// KeyFor returns the key of the section of type T owned by a module.
+func KeyFor[T any](key string) Key {
+	return Key{Key: key, Type: reflect.TypeFor[T]()}
+}
+
+// BindSection returns a pointer to the section of type T at key of root,
+// a config struct or a pointer to one, or false if root has none. Keys
+// match the file key or the name of the fields, ignoring case.
+func BindSection[T any](root any, key string) (*T, bool) {
+	v, ok := lookupSection(root, key)
+	if !ok {
+		return nil, false
+	}
+
+	switch s := v.Interface().(type) {
+	case T:
+		if v.CanAddr() {
+			return v.Addr().Interface().(*T), true
+		}
+		return &s, true
+	case *T:
+		return s, s != nil
+	}
+	return nil, false
+}
+
+// KeyReport reports the sections of a root config not bound to the keys
+// of the modules, by path.
+type KeyReport struct {
+	// Unused are the sections of a module type away from the module key,
+	// which the module ignores.
+	Unused []string
+
+	// Unknown are the sections at a module key of another type than the
+	// module one, which the module ignores.
+	Unknown []string
+
+	// Missing are the module keys without section, for which the modules
+	// apply their defaults.
+	Missing []string
+}
+
+// CheckKeys reports the sections of root which the keys do not bind.
+func CheckKeys(root any, keys []Key) KeyReport {
+	var report KeyReport
+
+	owned := make(map[reflect.Type][]string)
+	for _, k := range keys {
+		owned[k.Type] = append(owned[k.Type], normalizeKey(k.Key))
+
+		v, ok := lookupSection(root, k.Key)
+		switch {
+		case !ok || (v.Kind() == reflect.Pointer && v.IsNil()):
+			report.Missing = append(report.Missing, k.Key)
+		case v.Type() != k.Type && v.Type() != reflect.PointerTo(k.Type):
+			report.Unknown = append(report.Unknown,
+				fmt.Sprintf("%s (%s, want %s)", k.Key, v.Type(), k.Type))
+		}
+	}
+
+	v, ok := derefConfig(reflect.ValueOf(root))
+	if !ok {
+		return report
+	}
+	walkSections(v.Type(), nil, map[reflect.Type]bool{v.Type(): true}, func(keys []string, typ reflect.Type) {
+		ownedKeys, ok := owned[typ]
+		if !ok {
+			return
+		}
+		path := strings.Join(keys, ".")
+		for _, key := range ownedKeys {
+			if key == normalizeKey(path) {
+				return
+			}
+		}
+		report.Unused = append(report.Unused, fmt.Sprintf("%s (%s)", path, typ))
+	})
+
+	return report
+}
+
+// lookupSection returns the field at the dot separated key of root.
+func lookupSection(root any, key string) (reflect.Value, bool) {
+	v, ok := derefConfig(reflect.ValueOf(root))
+	if !ok || key == "" {
+		return reflect.Value{}, false
+	}
+
+	for _, name := range strings.Split(key, ".") {
+		if v, ok = derefConfig(v); !ok {
+			return reflect.Value{}, false
+		}
+
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.IsExported() && (strings.EqualFold(name, fileKey(field)) || strings.EqualFold(name, field.Name)) {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// derefConfig returns the struct v or v points to, if not nil.
+func derefConfig(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, v.Kind() == reflect.Struct
+}
+
+// walkSections calls fn with the file keys and the struct type of every
+// section of the struct typ.
+func walkSections(typ reflect.Type, keys []string, parents map[reflect.Type]bool, fn func(keys []string, typ reflect.Type)) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		key := fileKey(field)
+		if !field.IsExported() || key == "" || !isSection(field.Type) {
+			continue
+		}
+
+		sectionType := field.Type
+		if sectionType.Kind() == reflect.Pointer {
+			sectionType = sectionType.Elem()
+		}
+
+		fieldKeys := append(keys[:len(keys):len(keys)], key)
+		fn(fieldKeys, sectionType)
+
+		if !parents[sectionType] {
+			parents[sectionType] = true
+			walkSections(sectionType, fieldKeys, parents, fn)
+			delete(parents, sectionType)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package config_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-toho/toho/config"
+)
+
+type ServerConfig struct {
+	Addr string
+}
+
+type ModulesConfig struct {
+	Logger LogConfig
+	Admin  ServerConfig `json:"admin_server"`
+	Debug  *ServerConfig
+	Extra  struct {
+		Server ServerConfig
+	}
+	Metrics HTTPConfig
+}
+
+func TestBindSection(t *testing.T) {
+	root := &ModulesConfig{
+		Logger: LogConfig{Level: "debug"},
+		Admin:  ServerConfig{Addr: ":9000"},
+	}
+
+	logger, ok := config.BindSection[LogConfig](root, "logger")
+	if !ok || logger != &root.Logger {
+		t.Fatalf("BindSection(logger) = %p, %v, want %p", logger, ok, &root.Logger)
+	}
+
+	admin, ok := config.BindSection[ServerConfig](*root, "Admin_Server")
+	if !ok || admin.Addr != ":9000" {
+		t.Fatalf("BindSection(admin_server) = %+v, %v, want :9000", admin, ok)
+	}
+
+	if _, ok := config.BindSection[ServerConfig](root, "debug"); ok {
+		t.Error("BindSection(debug) bound a nil pointer section")
+	}
+	if _, ok := config.BindSection[ServerConfig](root, "metrics"); ok {
+		t.Error("BindSection(metrics) bound a section of another type")
+	}
+	if _, ok := config.BindSection[ServerConfig](nil, "admin_server"); ok {
+		t.Error("BindSection() bound a section of a nil root")
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	root := &ModulesConfig{}
+
+	report := config.CheckKeys(root, []config.Key{
+		config.KeyFor[LogConfig]("logger"),
+		config.KeyFor[ServerConfig]("debug"),
+		config.KeyFor[ServerConfig]("metrics"),
+		config.KeyFor[LogConfig]("tracing"),
+	})
+
+	want := config.KeyReport{
+		Unused:  []string{"admin_server (config_test.ServerConfig)", "extra.server (config_test.ServerConfig)"},
+		Unknown: []string{"metrics (config_test.HTTPConfig, want config_test.ServerConfig)"},
+		Missing: []string{"debug", "tracing"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("CheckKeys() = %+v, want %+v", report, want)
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import "sync"
+
+// notifyQueue delivers events one at a time, in the order they were
+// pushed. A drain called while another one is delivering, from a
+// subscriber or concurrently, leaves its events to that one, so
+// subscribers never see an older event after a newer one and may store or
+// reload without deadlocking.
+type notifyQueue[E any] struct {
+	mu       sync.Mutex // guards events and draining
+	events   []E
+	draining bool
+}
+
+// push queues e. Callers push under the lock ordering their events.
+func (q *notifyQueue[E]) push(e E) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.events = append(q.events, e)
+}
+
+// drain delivers the queued events, unless another drain is delivering
+// them already.
+func (q *notifyQueue[E]) drain(deliver func(E)) {
+	q.mu.Lock()
+	if q.draining {
+		q.mu.Unlock()
+		return
+	}
+	q.draining = true
+
+	for len(q.events) > 0 {
+		e := q.events[0]
+		q.events = q.events[1:]
+		q.mu.Unlock()
+
+		deliver(e)
+
+		q.mu.Lock()
+	}
+
+	q.draining = false
+	q.mu.Unlock()
+}
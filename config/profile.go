@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Common profile names.
+const (
+	ProfileLocal   = "local"
+	ProfileStaging = "staging"
+	ProfileProd    = "prod"
+)
+
+// ProfileEnv is the env var selecting the profile, ProfileFlag the flag
+// overriding it.
+const (
+	ProfileEnv  = "APP_ENV"
+	ProfileFlag = "profile"
+)
+
+// localOverlay is the suffix of the files overriding all other files.
+const localOverlay = "local"
+
+// OriginProfile is the origin kind of values set by profile defaults.
+const OriginProfile = "profile"
+
+// DetectProfile returns the profile selected by the --profile flag in
+// args, or else by the APP_ENV env var.
+func DetectProfile(args []string) string {
+	if profile := flagValue(args, ProfileFlag); profile != "" {
+		return profile
+	}
+	return os.Getenv(ProfileEnv)
+}
+
+// LayerFiles returns files with their overlays, in increasing precedence:
+// the files themselves, then their profile overlays, then their local
+// overlays. The overlays of config.yaml are config.<profile>.yaml and
+// config.local.yaml. Without a profile there are no overlays. Repeated
+// files are removed in both cases.
+func LayerFiles(files []string, profile string) []string {
+	if profile == "" {
+		return compactFiles(files)
+	}
+
+	layered := make([]string, 0, 3*len(files))
+	layered = append(layered, files...)
+	for _, overlay := range []string{profile, localOverlay} {
+		for _, file := range files {
+			layered = append(layered, overlayFile(file, overlay))
+		}
+	}
+
+	return compactFiles(layered)
+}
+
+func overlayFile(file, overlay string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + overlay + ext
+}
+
+// compactFiles removes repeated files, keeping the first occurrence.
+func compactFiles(files []string) []string {
+	seen := make(map[string]bool, len(files))
+	compact := files[:0:0]
+	for _, file := range files {
+		if !seen[file] {
+			seen[file] = true
+			compact = append(compact, file)
+		}
+	}
+	return compact
+}
+
+// ProfileDefaulter is implemented by config sections with defaults
+// specific to a profile. ProfileDefault returns a value of the section
+// type holding the defaults of profile, or nil if there are none.
+type ProfileDefaulter interface {
+	ProfileDefault(profile string) any
+}
+
+// ApplyProfileDefaults sets the fields of every ProfileDefaulter section
+// of structure that were left to their default value, according to
+// provenance, to the profile default. It returns the updated provenance.
+func ApplyProfileDefaults(structure any, profile string, provenance Provenance) (Provenance, error) {
+	applied, err := ApplyProfileDefaultsFunc(structure, profile, func(path string) bool {
+		origin, ok := provenance.Lookup(path)
+		return ok && origin.Kind != OriginDefault && origin.Kind != ""
+	})
+	if err != nil {
+		return provenance, err
+	}
+	return provenance.Replace(applied...), nil
+}
+
+// ApplyProfileDefaultsFunc is like ApplyProfileDefaults, the fields a
+// source set being the ones set reports. It returns the origins of the
+// fields set to the profile default.
+func ApplyProfileDefaultsFunc(structure any, profile string, set func(path string) bool) (Provenance, error) {
+	if err := StructCheck(structure); err != nil {
+		return nil, err
+	}
+	if profile == "" {
+		return nil, nil
+	}
+
+	p := &profileDefaults{profile: profile, set: set}
+	if err := p.section("", reflect.ValueOf(structure)); err != nil {
+		return nil, err
+	}
+	return p.applied, nil
+}
+
+type profileDefaults struct {
+	profile string
+	set     func(path string) bool
+	applied Provenance
+}
+
+func (p *profileDefaults) section(path string, val reflect.Value) error {
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if defaulter, ok := asProfileDefaulter(val); ok {
+		if defaults := defaulter.ProfileDefault(p.profile); defaults != nil {
+			def := reflect.ValueOf(defaults)
+			for def.Kind() == reflect.Pointer && def.Type() != val.Type() {
+				def = def.Elem()
+			}
+			if def.Type() != val.Type() {
+				return fmt.Errorf("config: %s: profile default %T does not match %s", path, defaults, val.Type())
+			}
+			p.apply(path, val, def)
+			return nil
+		}
+	}
+
+	for i := 0; i < val.NumField(); i++ {
+		if !val.Type().Field(i).IsExported() {
+			continue
+		}
+		if err := p.section(joinPath(path, val.Type().Field(i).Name), val.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply sets the leaf fields of val left to their default to def.
+func (p *profileDefaults) apply(path string, val, def reflect.Value) {
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := joinPath(path, field.Name)
+		if field.Type.Kind() == reflect.Struct {
+			p.apply(fieldPath, val.Field(i), def.Field(i))
+			continue
+		}
+
+		if p.set(fieldPath) {
+			continue
+		}
+
+		val.Field(i).Set(def.Field(i))
+		p.applied = append(p.applied, FieldOrigin{
+			Path:  fieldPath,
+			Value: fmt.Sprint(val.Field(i).Interface()),
+			Kind:  OriginProfile,
+			Name:  p.profile,
+		})
+	}
+}
+
+func asProfileDefaulter(val reflect.Value) (ProfileDefaulter, bool) {
+	if val.CanAddr() {
+		if defaulter, ok := val.Addr().Interface().(ProfileDefaulter); ok {
+			return defaulter, true
+		}
+	}
+	if val.CanInterface() {
+		defaulter, ok := val.Interface().(ProfileDefaulter)
+		return defaulter, ok
+	}
+	return nil, false
+}
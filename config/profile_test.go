@@ -0,0 +1,98 @@
+package config_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-toho/toho/config"
+)
+
+func TestDetectProfile(t *testing.T) {
+	t.Setenv(config.ProfileEnv, "staging")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "staging"},
+		{[]string{"--profile=prod"}, "prod"},
+		{[]string{"-profile", "local", "serve"}, "local"},
+		{[]string{"serve", "--", "--profile=prod"}, "staging"},
+	}
+	for _, tt := range tests {
+		if got := config.DetectProfile(tt.args); got != tt.want {
+			t.Errorf("DetectProfile(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLayerFiles(t *testing.T) {
+	files := []string{"config.yaml", "db.json", "config.yaml"}
+
+	got := config.LayerFiles(files, "")
+	if want := []string{"config.yaml", "db.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LayerFiles() = %q, want %q", got, want)
+	}
+
+	got = config.LayerFiles(files, config.ProfileProd)
+	want := []string{
+		"config.yaml", "db.json",
+		"config.prod.yaml", "db.prod.json",
+		"config.local.yaml", "db.local.json",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LayerFiles() = %q, want %q", got, want)
+	}
+}
+
+type profileLogConfig struct {
+	Level  string
+	Format string
+}
+
+func (profileLogConfig) ProfileDefault(profile string) any {
+	if profile == config.ProfileLocal {
+		return &profileLogConfig{Level: "debug", Format: "text"}
+	}
+	return nil
+}
+
+type profileConfig struct {
+	Name string
+	Log  profileLogConfig
+}
+
+func TestApplyProfileDefaults(t *testing.T) {
+	cfg := &profileConfig{Name: "orders", Log: profileLogConfig{Level: "warn", Format: "json"}}
+	provenance := config.Provenance{
+		{Path: "Name", Value: "orders", Kind: config.OriginFile, Name: "config.yaml"},
+		{Path: "Log.Level", Value: "warn", Kind: config.OriginEnv, Name: "APP_LOG_LEVEL"},
+		{Path: "Log.Format", Value: "json", Kind: config.OriginDefault},
+	}
+
+	got, err := config.ApplyProfileDefaults(cfg, config.ProfileLocal, provenance)
+	if err != nil {
+		t.Fatalf("ApplyProfileDefaults() error = %v", err)
+	}
+
+	want := profileConfig{Name: "orders", Log: profileLogConfig{Level: "warn", Format: "text"}}
+	if *cfg != want {
+		t.Errorf("config = %+v, want %+v", *cfg, want)
+	}
+
+	origin, _ := got.Lookup("Log.Format")
+	if origin.Kind != config.OriginProfile || origin.Name != config.ProfileLocal {
+		t.Errorf("origin of Log.Format = %+v, want profile %s", origin, config.ProfileLocal)
+	}
+	if provenance[2].Kind != config.OriginDefault {
+		t.Errorf("ApplyProfileDefaults() modified the given provenance")
+	}
+
+	cfg = &profileConfig{}
+	if _, err := config.ApplyProfileDefaults(cfg, config.ProfileProd, nil); err != nil {
+		t.Fatalf("ApplyProfileDefaults() error = %v", err)
+	}
+	if *cfg != (profileConfig{}) {
+		t.Errorf("config = %+v, want no prod defaults", *cfg)
+	}
+}
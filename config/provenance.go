@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+)
+
+// Origin kinds of a config field value.
+const (
+	OriginDefault = "default"
+	OriginFile    = "file"
+	OriginEnv     = "env"
+	OriginFlag    = "flag"
+)
+
+// FieldOrigin records where the final value of a config leaf field came
+// from.
+type FieldOrigin struct {
+	// Path is the dot separated path of the field, like "HTTP.Addr".
+	Path string `json:"path"`
+
+	// Value is the printed value of the field. Secret values are redacted.
+	Value string `json:"value"`
+
+	// Kind is one of the Origin kinds, empty if no source set the field.
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the file path, env var or flag name the value came from.
+	Name string `json:"name,omitempty"`
+}
+
+// Source returns the description of the origin, like "env ORDERS_HTTP_ADDR".
+func (o FieldOrigin) Source() string {
+	switch {
+	case o.Kind == "":
+		return "unset"
+	case o.Name == "":
+		return o.Kind
+	default:
+		return o.Kind + " " + o.Name
+	}
+}
+
+// Provenance lists the origin of every leaf field of a loaded config.
+type Provenance []FieldOrigin
+
+// ProvenanceSource is implemented by loaders recording the provenance of
+// the last loaded config.
+type ProvenanceSource interface {
+	Provenance() Provenance
+}
+
+// Lookup returns the origin of the field at path.
+func (p Provenance) Lookup(path string) (FieldOrigin, bool) {
+	for _, o := range p {
+		if o.Path == path {
+			return o, true
+		}
+	}
+	return FieldOrigin{}, false
+}
+
+// Replace returns a copy of p with the origins of the same path replaced
+// by origins, and the others appended.
+func (p Provenance) Replace(origins ...FieldOrigin) Provenance {
+	replaced := slices.Clone(p)
+	for _, o := range origins {
+		i := slices.IndexFunc(replaced, func(r FieldOrigin) bool { return r.Path == o.Path })
+		if i < 0 {
+			replaced = append(replaced, o)
+			continue
+		}
+		replaced[i] = o
+	}
+	return replaced
+}
+
+// WriteText writes the provenance as a table of fields, values and,
+// if sources is set, their origin.
+func (p Provenance) WriteText(w io.Writer, sources bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, o := range p {
+		if sources {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", o.Path, o.Value, o.Source())
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", o.Path, o.Value)
+		}
+	}
+	return tw.Flush()
+}
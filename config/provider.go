@@ -3,6 +3,16 @@ package config
 const (
 	NamedConfigPointerIn  = "config.pointer.in"
 	NamedConfigPointerOut = "config.pointer.out"
+	NamedConfigLoadFunc   = "config.load.func"
+	NamedProfile          = "config.profile"
+	NamedSearchPaths      = "config.search.paths"
+	NamedSearchPolicy     = "config.search.policy"
 
-	GroupConfigFiles = "config.files"
+	GroupConfigKeys        = "config.keys"
+	GroupConfigSubscribers = "config.subscribers"
 )
+
+// GroupConfigFiles groups the supplied config files, of type Files. Plain
+// string paths, its former element type, are still loaded, first of the
+// priority 0 files and in random order.
+const GroupConfigFiles = "config.files"
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// FieldNames are the env var and flag setting a config field.
+type FieldNames struct {
+	Env  string `json:"env,omitempty"`
+	Flag string `json:"flag,omitempty"`
+}
+
+// FieldNamer is implemented by loaders reading env vars or flags.
+// FieldNames returns the names of every leaf field of structure by path.
+type FieldNamer interface {
+	FieldNames(structure any) map[string]FieldNames
+}
+
+// FieldDoc documents a config leaf field.
+type FieldDoc struct {
+	// Path is the dot separated path of the field, like "HTTP.Addr".
+	Path string `json:"path"`
+
+	// Key is the dot separated key of the field in config files.
+	Key string `json:"key"`
+
+	Type     string `json:"type"`
+	Default  string `json:"default,omitempty"`
+	Usage    string `json:"usage,omitempty"`
+	Required bool   `json:"required,omitempty"`
+
+	FieldNames
+}
+
+// Reference documents every leaf field of a config.
+type Reference []FieldDoc
+
+// NewReference returns the reference of structure, with the env vars and
+// flags named by namer, if not nil.
+func NewReference(structure any, namer FieldNamer) (Reference, error) {
+	if err := StructCheck(structure); err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeOf(structure)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	var names map[string]FieldNames
+	if namer != nil {
+		names = namer.FieldNames(structure)
+	}
+
+	var ref Reference
+	walkLeaves(typ, nil, nil, func(path, keys []string, field reflect.StructField) {
+		doc := FieldDoc{
+			Path:     strings.Join(path, "."),
+			Key:      strings.Join(keys, "."),
+			Type:     typeName(field.Type),
+			Usage:    field.Tag.Get(usageTag),
+			Required: isRequired(field),
+		}
+		if field.Type != secretType {
+			doc.Default = field.Tag.Get(defaultTag)
+		}
+		doc.FieldNames = names[doc.Path]
+		ref = append(ref, doc)
+	})
+	return ref, nil
+}
+
+// walkLeaves calls fn with the path, the file keys and the field of every
+// leaf field of the struct typ, the fields of embedded structs being
+// leaves of the parent.
+func walkLeaves(typ reflect.Type, path, keys []string, fn func(path, keys []string, field reflect.StructField)) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		key := fileKey(field)
+		if !field.IsExported() || key == "" {
+			continue
+		}
+
+		fieldPath := append(path[:len(path):len(path)], field.Name)
+		fieldKeys := append(keys[:len(keys):len(keys)], key)
+
+		if isSection(field.Type) {
+			sectionType := field.Type
+			if sectionType.Kind() == reflect.Pointer {
+				sectionType = sectionType.Elem()
+			}
+			// embedded structs are part of the parent, like aconfig reads them
+			if field.Anonymous {
+				fieldPath, fieldKeys = path, keys
+			}
+			if sectionType != typ {
+				walkLeaves(sectionType, fieldPath, fieldKeys, fn)
+			}
+			continue
+		}
+		fn(fieldPath, fieldKeys, field)
+	}
+}
+
+func typeName(typ reflect.Type) string {
+	switch typ {
+	case secretType:
+		return "secret"
+	case durationType:
+		return "duration"
+	case timeType:
+		return "time"
+	}
+	return typ.String()
+}
+
+// WriteMarkdown writes the reference as a Markdown table.
+func (r Reference) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Env | Flag | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+
+	for _, doc := range r {
+		usage := doc.Usage
+		if doc.Required {
+			usage = strings.TrimSpace("Required. " + usage)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			markdownCode(doc.Key),
+			markdownCell(doc.Type),
+			markdownCode(doc.Default),
+			markdownCode(doc.Env),
+			markdownCode(doc.Flag),
+			markdownCell(usage),
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteEnv writes the reference as an env file setting the fields read
+// from env vars to their default.
+func (r Reference) WriteEnv(w io.Writer) error {
+	var b strings.Builder
+	for _, doc := range r {
+		if doc.Env == "" {
+			continue
+		}
+
+		fmt.Fprintf(&b, "# %s (%s)", doc.Key, doc.Type)
+		if doc.Required {
+			b.WriteString(", required")
+		}
+		if doc.Usage != "" {
+			b.WriteString(": " + doc.Usage)
+		}
+		fmt.Fprintf(&b, "\n%s=%s\n", doc.Env, doc.Default)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + markdownCell(s) + "`"
+}
+
+// FieldNames returns the names of the env var and flag of the first
+// EnvSource and FlagSource of the loader, by field path.
+func (l *SourceLoader) FieldNames(structure any) map[string]FieldNames {
+	var (
+		env   *EnvSource
+		flags *FlagSource
+	)
+	for _, source := range l.Sources {
+		switch s := source.(type) {
+		case *EnvSource:
+			if env == nil {
+				env = s
+			}
+		case *FlagSource:
+			if flags == nil {
+				flags = s
+			}
+		}
+	}
+
+	typ := reflect.TypeOf(structure)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := make(map[string]FieldNames)
+	walkLeaves(typ, nil, nil, func(path, _ []string, _ reflect.StructField) {
+		// sources are keyed by field name
+		keys := make([]string, len(path))
+		for i, name := range path {
+			keys[i] = strings.ToLower(strings.Join(splitWords(name), "_"))
+		}
+
+		var n FieldNames
+		if env != nil {
+			n.Env = env.Origin(strings.ToUpper(strings.Join(keys, "_"))).Name
+		}
+		if flags != nil {
+			n.Flag = flags.Origin(strings.Join(keys, ".")).Name
+		}
+		names[strings.Join(path, ".")] = n
+	})
+	return names
+}
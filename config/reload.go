@@ -0,0 +1,251 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultWatchInterval is the default interval between checks for config
+// file changes.
+const DefaultWatchInterval = 5 * time.Second
+
+var errReloadNotSupported = errors.New("config: reload not supported, no loader")
+
+// LoadFunc loads config into the struct pointed to by dst.
+type LoadFunc func(dst any) error
+
+// Load implements Loader.
+func (f LoadFunc) Load(dst any) error {
+	return f(dst)
+}
+
+// ReloaderOption is a reloader option.
+type ReloaderOption func(o *reloaderOptions)
+
+// reloaderOptions is a reloader options.
+type reloaderOptions struct {
+	validate func(any) error
+	onError  func(error)
+}
+
+// WithValidator with a function validating a freshly loaded config before
+// it replaces the current one. Validate is used by default.
+func WithValidator(fn func(any) error) ReloaderOption {
+	return func(o *reloaderOptions) { o.validate = fn }
+}
+
+// WithErrorHandler with a function receiving the errors of reloads
+// triggered by signals or file changes.
+func WithErrorHandler(fn func(error)) ReloaderOption {
+	return func(o *reloaderOptions) { o.onError = fn }
+}
+
+// Reloader loads the config into a fresh struct, validates it, swaps it
+// with the current one and notifies the subscribers of changed sections.
+type Reloader struct {
+	opts reloaderOptions
+	load LoadFunc
+
+	reloadMu sync.Mutex // serializes loads and swaps
+	mu       sync.Mutex // guards subs
+	current  atomic.Value
+	subs     []reloaderSub
+	nextID   int
+	changes  notifyQueue[map[reflect.Type][]section]
+}
+
+type reloaderSub struct {
+	id int
+	s  Subscriber
+}
+
+// NewReloader returns a reloader for the config pointed to by current.
+// A nil load makes every reload fail.
+func NewReloader(current any, load LoadFunc, opts ...ReloaderOption) (*Reloader, error) {
+	if err := StructCheck(current); err != nil {
+		return nil, err
+	}
+	if reflect.TypeOf(current).Kind() != reflect.Pointer {
+		return nil, fmt.Errorf("config: expecting pointer to struct, got %T", current)
+	}
+
+	o := reloaderOptions{
+		validate: Validate,
+		onError:  func(error) {},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r := &Reloader{
+		opts: o,
+		load: load,
+	}
+	r.current.Store(current)
+
+	return r, nil
+}
+
+// Current returns a pointer to the current config. The pointed struct must
+// not be modified, a reload replaces it instead.
+func (r *Reloader) Current() any {
+	return r.current.Load()
+}
+
+// Subscribe registers s and returns a function removing it. Subscribers
+// are notified in the order they subscribed.
+func (r *Reloader) Subscribe(s Subscriber) (cancel func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+	r.subs = append(r.subs, reloaderSub{id: id, s: s})
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.subs = slices.DeleteFunc(r.subs, func(sub reloaderSub) bool { return sub.id == id })
+	}
+}
+
+// Reload loads and validates a fresh config. On success it replaces the
+// current config and notifies subscribers of the sections that changed.
+// Subscribers are notified without locks held, so they may subscribe or
+// reload, and one reload at a time in the order of the swaps: a reload
+// made by a subscriber or while another one is notifying returns once
+// swapped, and that one notifies its changes next.
+func (r *Reloader) Reload(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.load == nil {
+		return errReloadNotSupported
+	}
+
+	if err := r.swap(); err != nil {
+		return fmt.Errorf("config: reload: %w", err)
+	}
+
+	r.changes.drain(r.notify)
+	return nil
+}
+
+// notify notifies the subscribers of the changed sections.
+func (r *Reloader) notify(changes map[reflect.Type][]section) {
+	r.mu.Lock()
+	subs := slices.Clone(r.subs)
+	r.mu.Unlock()
+
+	for _, sub := range subs {
+		for _, c := range changes[sub.s.Type()] {
+			sub.s.Notify(c.old.Interface(), c.new.Interface())
+		}
+	}
+}
+
+// swap loads and validates a fresh config, replaces the current one with
+// it and queues the changed sections.
+func (r *Reloader) swap() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	old := r.current.Load()
+	fresh := reflect.New(reflect.TypeOf(old).Elem())
+
+	if err := r.load(fresh.Interface()); err != nil {
+		return err
+	}
+	if err := r.opts.validate(fresh.Interface()); err != nil {
+		return err
+	}
+
+	r.current.Store(fresh.Interface())
+	r.changes.push(changedSections(reflect.ValueOf(old), fresh))
+	return nil
+}
+
+// ReloadOnSignal reloads the config whenever one of the signals is
+// received, SIGHUP by default, until ctx is done.
+func (r *Reloader) ReloadOnSignal(ctx context.Context, signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	go func() {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				r.reload(ctx)
+			}
+		}
+	}()
+}
+
+// ReloadOnFileChange reloads the config whenever one of the files is
+// created, removed or modified, until ctx is done. Files are checked every
+// interval, or DefaultWatchInterval if it is not positive.
+func (r *Reloader) ReloadOnFileChange(ctx context.Context, files []string, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	last := statFiles(files)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current := statFiles(files)
+				if current == last {
+					continue
+				}
+				last = current
+				r.reload(ctx)
+			}
+		}
+	}()
+}
+
+func (r *Reloader) reload(ctx context.Context) {
+	if err := r.Reload(ctx); err != nil {
+		r.opts.onError(err)
+	}
+}
+
+// statFiles returns a fingerprint of the files size and modification time.
+func statFiles(files []string) string {
+	var fingerprint string
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			fingerprint += file + ":-;"
+			continue
+		}
+		fingerprint += fmt.Sprintf("%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return fingerprint
+}
@@ -0,0 +1,319 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/config"
+)
+
+type LogConfig struct {
+	Level string
+}
+
+type HTTPConfig struct {
+	Addr string
+}
+
+type RootConfig struct {
+	Log  LogConfig
+	HTTP HTTPConfig
+	DB   *HTTPConfig
+}
+
+func loadFrom(src *RootConfig) config.LoadFunc {
+	return func(dst any) error {
+		*dst.(*RootConfig) = *src
+		return nil
+	}
+}
+
+func TestReloadSwapsConfig(t *testing.T) {
+	current := &RootConfig{Log: LogConfig{Level: "info"}}
+	src := &RootConfig{Log: LogConfig{Level: "debug"}}
+
+	r, err := config.NewReloader(current, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	got := r.Current().(*RootConfig)
+	if got == current {
+		t.Fatal("Current() returned the old config, want a fresh struct")
+	}
+	if got.Log.Level != "debug" {
+		t.Fatalf("Current().Log.Level = %q, want debug", got.Log.Level)
+	}
+	if current.Log.Level != "info" {
+		t.Fatalf("old config modified, Level = %q", current.Log.Level)
+	}
+}
+
+func TestReloadNotifiesChangedSectionsOnly(t *testing.T) {
+	current := &RootConfig{
+		Log:  LogConfig{Level: "info"},
+		HTTP: HTTPConfig{Addr: ":8080"},
+	}
+	src := &RootConfig{
+		Log:  LogConfig{Level: "debug"},
+		HTTP: HTTPConfig{Addr: ":8080"},
+	}
+
+	r, err := config.NewReloader(current, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logCalls, httpCalls, rootCalls int
+	r.Subscribe(config.Watch(func(old, new LogConfig) {
+		logCalls++
+		if old.Level != "info" || new.Level != "debug" {
+			t.Errorf("LogConfig change = %q -> %q, want info -> debug", old.Level, new.Level)
+		}
+	}))
+	r.Subscribe(config.Watch(func(_, _ HTTPConfig) { httpCalls++ }))
+	r.Subscribe(config.Watch(func(_, _ RootConfig) { rootCalls++ }))
+
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if logCalls != 1 {
+		t.Errorf("LogConfig subscriber called %d times, want 1", logCalls)
+	}
+	if httpCalls != 0 {
+		t.Errorf("HTTPConfig subscriber called %d times, want 0", httpCalls)
+	}
+	if rootCalls != 1 {
+		t.Errorf("RootConfig subscriber called %d times, want 1", rootCalls)
+	}
+
+	// nothing changed
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if logCalls != 1 || rootCalls != 1 {
+		t.Errorf("subscribers notified without changes: log=%d root=%d", logCalls, rootCalls)
+	}
+}
+
+func TestReloadNotifiesPointerSections(t *testing.T) {
+	current := &RootConfig{}
+	src := &RootConfig{DB: &HTTPConfig{Addr: "db:5432"}}
+
+	r, err := config.NewReloader(current, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got *HTTPConfig
+	r.Subscribe(config.Watch(func(_, new *HTTPConfig) { got = new }))
+
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got == nil || got.Addr != "db:5432" {
+		t.Fatalf("*HTTPConfig subscriber got %+v, want db:5432", got)
+	}
+}
+
+func TestReloadKeepsCurrentOnValidationError(t *testing.T) {
+	current := &RootConfig{Log: LogConfig{Level: "info"}}
+	src := &RootConfig{Log: LogConfig{Level: "loud"}}
+
+	errInvalid := errors.New("invalid level")
+	r, err := config.NewReloader(current, loadFrom(src),
+		config.WithValidator(func(c any) error {
+			if c.(*RootConfig).Log.Level == "loud" {
+				return errInvalid
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notified := false
+	r.Subscribe(config.Watch(func(_, _ LogConfig) { notified = true }))
+
+	if err := r.Reload(context.Background()); !errors.Is(err, errInvalid) {
+		t.Fatalf("Reload() error = %v, want %v", err, errInvalid)
+	}
+	if r.Current() != current {
+		t.Fatal("Current() changed after a failed reload")
+	}
+	if notified {
+		t.Fatal("subscriber notified after a failed reload")
+	}
+}
+
+func TestReloadWithoutLoaderFails(t *testing.T) {
+	r, err := config.NewReloader(&RootConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Reload(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("Reload() error = %v, want not supported error", err)
+	}
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	src := &RootConfig{Log: LogConfig{Level: "debug"}}
+	r, err := config.NewReloader(&RootConfig{}, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	cancel := r.Subscribe(config.Watch(func(_, _ LogConfig) { calls++ }))
+	cancel()
+
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("canceled subscriber called %d times", calls)
+	}
+}
+
+func TestReloadNotifiesInSubscribeOrder(t *testing.T) {
+	src := &RootConfig{Log: LogConfig{Level: "debug"}}
+	r, err := config.NewReloader(&RootConfig{}, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []string
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		r.Subscribe(config.Watch(func(_, _ LogConfig) { calls = append(calls, name) }))
+	}
+
+	for i := 0; i < 5; i++ {
+		calls = nil
+		src.Log.Level = strings.Repeat("v", i+1)
+		if err := r.Reload(context.Background()); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+		if got := strings.Join(calls, ""); got != "abcde" {
+			t.Fatalf("notification order = %s, want abcde", got)
+		}
+	}
+}
+
+func TestSubscribersMaySubscribeAndReload(t *testing.T) {
+	src := &RootConfig{Log: LogConfig{Level: "debug"}}
+	r, err := config.NewReloader(&RootConfig{}, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reloadErr error
+	r.Subscribe(config.Watch(func(_, new LogConfig) {
+		r.Subscribe(config.Watch(func(_, _ HTTPConfig) {}))
+		if new.Level == "debug" {
+			src.Log.Level = "warn"
+			reloadErr = r.Reload(context.Background())
+		}
+	}))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Reload(context.Background())
+	}()
+	select {
+	case err := <-done:
+		if err != nil || reloadErr != nil {
+			t.Fatalf("Reload() errors = %v, %v", err, reloadErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Reload() blocked on a subscriber")
+	}
+
+	if got := r.Current().(*RootConfig).Log.Level; got != "warn" {
+		t.Fatalf("Current().Log.Level = %q, want warn", got)
+	}
+}
+
+func TestReloadNotifiesInOrderWhenSubscriberReloads(t *testing.T) {
+	src := &RootConfig{Log: LogConfig{Level: "debug"}}
+	r, err := config.NewReloader(&RootConfig{Log: LogConfig{Level: "info"}}, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Subscribe(config.Watch(func(_, new LogConfig) {
+		if new.Level == "debug" {
+			src.Log.Level = "warn"
+			if err := r.Reload(context.Background()); err != nil {
+				t.Errorf("Reload() from subscriber error = %v", err)
+			}
+		}
+	}))
+
+	var calls []string
+	r.Subscribe(config.Watch(func(old, new LogConfig) {
+		calls = append(calls, old.Level+"->"+new.Level)
+	}))
+
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	want := "info->debug debug->warn"
+	if got := strings.Join(calls, " "); got != want {
+		t.Fatalf("second subscriber calls = %s, want %s", got, want)
+	}
+}
+
+func TestReloadOnFileChange(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.txt")
+	if err := os.WriteFile(file, []byte("info"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	load := func(dst any) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		dst.(*RootConfig).Log.Level = string(data)
+		return nil
+	}
+
+	r, err := config.NewReloader(&RootConfig{Log: LogConfig{Level: "info"}}, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan string, 1)
+	r.Subscribe(config.Watch(func(_, new LogConfig) { changed <- new.Level }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.ReloadOnFileChange(ctx, []string{file}, 10*time.Millisecond)
+
+	if err := os.WriteFile(file, []byte("debug"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case level := <-changed:
+		if level != "debug" {
+			t.Fatalf("reloaded level = %q, want debug", level)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("config not reloaded after file change")
+	}
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SchemaDraft is the JSON Schema version of the generated schemas.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// usageTag holds the description of a config field.
+const usageTag = "usage"
+
+// durationPattern matches the time.ParseDuration format.
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// Schema is a JSON Schema of a config value.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type      string `json:"type,omitempty"`
+	Format    string `json:"format,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	WriteOnly bool   `json:"writeOnly,omitempty"`
+	Default   any    `json:"default,omitempty"`
+	Enum      []any  `json:"enum,omitempty"`
+
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema of config files holding structure,
+// with the keys aconfigo.Loader reads: the json or yaml tag of the fields,
+// or their snake cased name, the fields of embedded structs being keys of
+// the parent. The `default`, `usage` and `validate` tags of
+// the fields set their default, description and constraints.
+func JSONSchema(structure any) (*Schema, error) {
+	if err := StructCheck(structure); err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeOf(structure)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	schema := typeSchema(typ, map[reflect.Type]bool{})
+	schema.Schema = SchemaDraft
+	schema.Title = typ.Name()
+	return schema, nil
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func typeSchema(typ reflect.Type, parents map[reflect.Type]bool) *Schema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == secretType:
+		return &Schema{Type: "string", WriteOnly: true}
+	case typ == durationType:
+		return &Schema{Type: "string", Pattern: durationPattern}
+	case typ == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflect.PointerTo(typ).Implements(textUnmarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: typeSchema(typ.Elem(), parents)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(typ.Elem(), parents)}
+	case reflect.Struct:
+		return structSchema(typ, parents)
+	default:
+		return &Schema{}
+	}
+}
+
+func structSchema(typ reflect.Type, parents map[reflect.Type]bool) *Schema {
+	schema := &Schema{Type: "object"}
+	if parents[typ] {
+		return schema
+	}
+	parents[typ] = true
+	defer delete(parents, typ)
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		key := fileKey(field)
+		if !field.IsExported() || key == "" {
+			continue
+		}
+
+		// embedded structs are part of the parent, like aconfig reads them
+		if field.Anonymous && isSection(field.Type) {
+			embedded := typeSchema(field.Type, parents)
+			for name, prop := range embedded.Properties {
+				if _, ok := schema.Properties[name]; ok {
+					continue
+				}
+				if schema.Properties == nil {
+					schema.Properties = make(map[string]*Schema)
+				}
+				schema.Properties[name] = prop
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		fieldSchema := typeSchema(field.Type, parents)
+		fieldSchema.Description = field.Tag.Get(usageTag)
+		if def, ok := field.Tag.Lookup(defaultTag); ok && field.Type != secretType {
+			fieldSchema.Default = schemaValue(field.Type, def)
+		}
+		if isRequired(field) {
+			schema.Required = append(schema.Required, key)
+		}
+		applyRules(fieldSchema, field.Type, field.Tag.Get(validateTag))
+
+		if schema.Properties == nil {
+			schema.Properties = make(map[string]*Schema)
+		}
+		schema.Properties[key] = fieldSchema
+	}
+
+	return schema
+}
+
+// applyRules sets the constraints of schema from the validation rules.
+func applyRules(schema *Schema, typ reflect.Type, rules string) {
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "min", "max":
+			applyLimit(schema, typ, name, arg)
+		case "oneof":
+			for _, option := range strings.Fields(arg) {
+				schema.Enum = append(schema.Enum, schemaValue(typ, option))
+			}
+		}
+	}
+}
+
+func applyLimit(schema *Schema, typ reflect.Type, name, arg string) {
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		// durations limits are not expressible
+		return
+	}
+	n := int(limit)
+
+	switch schema.Type {
+	case "integer", "number":
+		if name == "min" {
+			schema.Minimum = &limit
+		} else {
+			schema.Maximum = &limit
+		}
+	case "string":
+		if name == "min" {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if name == "min" {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	}
+}
+
+// schemaValue returns s parsed as a value of typ, or s itself if it does
+// not parse or is encoded as a string.
+func schemaValue(typ reflect.Type, s string) any {
+	val := reflect.New(typ).Elem()
+	if err := assignString(val, s); err != nil {
+		return s
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ, val = typ.Elem(), val.Elem()
+	}
+	if typ == durationType || reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return s
+	}
+	return val.Interface()
+}
+
+// isRequired reports whether field has the required validation rule or
+// the aconfig required tag.
+func isRequired(field reflect.StructField) bool {
+	if field.Tag.Get("required") == "true" {
+		return true
+	}
+	for _, rule := range strings.Split(field.Tag.Get(validateTag), ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// fileKey returns the key of field in config files, its json or yaml tag
+// or its snake cased name, or "" if it is skipped.
+func fileKey(field reflect.StructField) string {
+	for _, format := range []string{"json", "yaml"} {
+		tag, ok := field.Tag.Lookup(format)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return strings.ToLower(strings.Join(splitWords(field.Name), "_"))
+}
+
+// splitWords splits a Go name in words, keeping acronyms together:
+// ReadTimeout is Read Timeout, and HTTPAddr is HTTP Addr.
+func splitWords(name string) []string {
+	runes := []rune(name)
+
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case unicode.IsLower(prev) && unicode.IsUpper(cur),
+			unicode.IsLetter(prev) != unicode.IsLetter(cur),
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && unicode.IsLower(next):
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
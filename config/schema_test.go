@@ -0,0 +1,170 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/config"
+)
+
+type schemaHTTPConfig struct {
+	Addr        string        `default:":8080" usage:"listen address" validate:"required"`
+	ReadTimeout time.Duration `default:"5s"`
+	MaxConns    uint          `validate:"max=100"`
+}
+
+type schemaConfig struct {
+	Name     string `json:"service_name" validate:"min=3"`
+	Format   string `default:"json" validate:"oneof=json text"`
+	Level    int    `default:"2" validate:"oneof=1 2 3"`
+	Token    config.Secret
+	HTTP     *schemaHTTPConfig
+	Tags     map[string]string
+	Hosts    []string `validate:"min=1"`
+	Internal string   `json:"-"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := config.JSONSchema(&schemaConfig{})
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	props := got["properties"].(map[string]any)
+	if _, ok := props["internal"]; ok {
+		t.Errorf("schema has the skipped field internal")
+	}
+
+	http := props["http"].(map[string]any)
+	httpProps := http["properties"].(map[string]any)
+
+	checks := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"$schema", got["$schema"], config.SchemaDraft},
+		{"title", got["title"], "schemaConfig"},
+		{"service_name.minLength", props["service_name"].(map[string]any)["minLength"], 3.0},
+		{"format.enum", props["format"].(map[string]any)["enum"], []any{"json", "text"}},
+		{"level.enum", props["level"].(map[string]any)["enum"], []any{1.0, 2.0, 3.0}},
+		{"level.default", props["level"].(map[string]any)["default"], 2.0},
+		{"token.writeOnly", props["token"].(map[string]any)["writeOnly"], true},
+		{"tags.additionalProperties", props["tags"].(map[string]any)["additionalProperties"], map[string]any{"type": "string"}},
+		{"hosts.minItems", props["hosts"].(map[string]any)["minItems"], 1.0},
+		{"http.required", http["required"], []any{"addr"}},
+		{"http.addr.description", httpProps["addr"].(map[string]any)["description"], "listen address"},
+		{"http.read_timeout.default", httpProps["read_timeout"].(map[string]any)["default"], "5s"},
+		{"http.max_conns.maximum", httpProps["max_conns"].(map[string]any)["maximum"], 100.0},
+	}
+	for _, c := range checks {
+		if !jsonEqual(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func jsonEqual(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
+
+func TestReference(t *testing.T) {
+	loader := config.NewSourceLoader(config.Env("ORDERS"), config.Flags("orders"))
+
+	ref, err := config.NewReference(&schemaConfig{}, loader)
+	if err != nil {
+		t.Fatalf("NewReference() error = %v", err)
+	}
+
+	var md bytes.Buffer
+	if err := ref.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	wantRow := "| `http.read_timeout` | duration | `5s` | `ORDERS_HTTP_READ_TIMEOUT` | `-orders.http.read_timeout` |  |"
+	if !strings.Contains(md.String(), wantRow) {
+		t.Errorf("markdown does not contain %q:\n%s", wantRow, md.String())
+	}
+	if !strings.Contains(md.String(), "Required. listen address") {
+		t.Errorf("markdown does not mark http.addr required:\n%s", md.String())
+	}
+
+	var env bytes.Buffer
+	if err := ref.WriteEnv(&env); err != nil {
+		t.Fatal(err)
+	}
+	wantEnv := "# http.addr (string), required: listen address\nORDERS_HTTP_ADDR=:8080\n"
+	if !strings.Contains(env.String(), wantEnv) {
+		t.Errorf("env does not contain %q:\n%s", wantEnv, env.String())
+	}
+}
+
+type SchemaSamplingRule struct {
+	First int `default:"100"`
+}
+
+type schemaSamplingConfig struct {
+	SchemaSamplingRule
+	Enabled bool   `yaml:"on"`
+	Format  string `json:"fmt" yaml:"format"`
+}
+
+type schemaEmbeddingConfig struct {
+	Sampling schemaSamplingConfig
+}
+
+func TestSchemaAndReferenceFlattenEmbeddedStructs(t *testing.T) {
+	schema, err := config.JSONSchema(&schemaEmbeddingConfig{})
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+
+	props := schema.Properties["sampling"].Properties
+	var keys []string
+	for key := range props {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if got, want := strings.Join(keys, " "), "first fmt on"; got != want {
+		t.Fatalf("sampling properties = %s, want %s", got, want)
+	}
+
+	loader := config.NewSourceLoader(config.Env("ORDERS"), config.Flags("orders"))
+	ref, err := config.NewReference(&schemaEmbeddingConfig{}, loader)
+	if err != nil {
+		t.Fatalf("NewReference() error = %v", err)
+	}
+
+	want := []config.FieldDoc{
+		{
+			Path: "Sampling.First", Key: "sampling.first", Type: "int", Default: "100",
+			FieldNames: config.FieldNames{Env: "ORDERS_SAMPLING_FIRST", Flag: "-orders.sampling.first"},
+		},
+		{
+			Path: "Sampling.Enabled", Key: "sampling.on", Type: "bool",
+			FieldNames: config.FieldNames{Env: "ORDERS_SAMPLING_ENABLED", Flag: "-orders.sampling.enabled"},
+		},
+		{
+			Path: "Sampling.Format", Key: "sampling.fmt", Type: "string",
+			FieldNames: config.FieldNames{Env: "ORDERS_SAMPLING_FORMAT", Flag: "-orders.sampling.format"},
+		},
+	}
+	if !slices.Equal(ref, want) {
+		t.Fatalf("NewReference() = %+v, want %+v", ref, want)
+	}
+}
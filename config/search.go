@@ -0,0 +1,163 @@
+package config
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// ConfigFileFlag is the flag overriding the config files, and
+// ConfigFileEnvSuffix the suffix of the env var overriding them after
+// the env prefix of the app, like ORDERS_CONFIG.
+const (
+	ConfigFileFlag      = "config"
+	ConfigFileEnvSuffix = "_CONFIG"
+)
+
+// SearchPolicy selects which of the config files found in the search
+// paths are loaded.
+type SearchPolicy int
+
+const (
+	// FirstMatch loads every file from the first search path holding it.
+	FirstMatch SearchPolicy = iota
+
+	// MergeAll loads every file from all the search paths holding it,
+	// the files of the first search paths taking precedence.
+	MergeAll
+)
+
+// Files are config files supplied to an app, in increasing precedence.
+// Since groups of values come in random order, OrderFiles orders the files
+// of several supplies by priority, then in the order they were created,
+// the Files literals not created by NewFiles first.
+type Files struct {
+	Priority int
+	Paths    []string
+
+	seq uint64
+}
+
+var filesSeq atomic.Uint64
+
+// NewFiles returns the files at paths, with priority.
+func NewFiles(priority int, paths ...string) Files {
+	return Files{Priority: priority, Paths: paths, seq: filesSeq.Add(1)}
+}
+
+// OrderFiles returns the paths of files in increasing precedence: the
+// files of lower priority first, and of the same priority in the order
+// they were created.
+func OrderFiles(files []Files) []string {
+	files = slices.Clone(files)
+	slices.SortStableFunc(files, func(a, b Files) int {
+		return cmp.Or(cmp.Compare(a.Priority, b.Priority), cmp.Compare(a.seq, b.seq))
+	})
+
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Paths...)
+	}
+	return paths
+}
+
+// FileLister is implemented by loaders reading config files. ConfigFiles
+// returns the files read by the next load, found or not.
+type FileLister interface {
+	ConfigFiles() []string
+}
+
+// DefaultSearchPaths returns the working directory, then the config
+// directories of app: the user one, $XDG_CONFIG_HOME/<app> or
+// ~/.config/<app> on Linux, and /etc/<app>. Only the working directory
+// is searched without app name.
+func DefaultSearchPaths(app string) []string {
+	paths := []string{"."}
+	if app == "" {
+		return paths
+	}
+
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, app))
+	}
+	return append(paths, filepath.Join("/etc", app))
+}
+
+// SearchFiles returns the files found in the search paths, in increasing
+// precedence. Absolute files are not searched. With the FirstMatch policy,
+// a file found nowhere is returned in the first search path.
+func SearchFiles(files, paths []string, policy SearchPolicy) []string {
+	if len(paths) == 0 {
+		return files
+	}
+
+	var found []string
+	for _, file := range files {
+		if filepath.IsAbs(file) {
+			found = append(found, file)
+			continue
+		}
+
+		var matches []string
+		for _, path := range paths {
+			candidate := filepath.Join(path, file)
+			if _, err := os.Stat(candidate); err == nil {
+				matches = append(matches, candidate)
+			}
+		}
+
+		switch {
+		case len(matches) == 0:
+			if policy == FirstMatch {
+				found = append(found, filepath.Join(paths[0], file))
+			}
+		case policy == FirstMatch:
+			found = append(found, matches[0])
+		default:
+			slices.Reverse(matches)
+			found = append(found, matches...)
+		}
+	}
+	return found
+}
+
+// DetectConfigFile returns the config file selected by the --config flag
+// in args, or else by the env var env, if not empty.
+func DetectConfigFile(args []string, env string, lookupEnv func(string) (string, bool)) string {
+	if file := flagValue(args, ConfigFileFlag); file != "" {
+		return file
+	}
+	if env == "" {
+		return ""
+	}
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	file, _ := lookupEnv(env)
+	return file
+}
+
+// flagValue returns the value of the flag name in args.
+func flagValue(args []string, name string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+
+		flagName, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || flagName != name {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-toho/toho/config"
+)
+
+func TestSearchFiles(t *testing.T) {
+	cwd, user, etc := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, file := range []string{
+		filepath.Join(user, "config.yaml"),
+		filepath.Join(etc, "config.yaml"),
+		filepath.Join(etc, "db.yaml"),
+	} {
+		if err := os.WriteFile(file, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths := []string{cwd, user, etc}
+	files := []string{"config.yaml", "db.yaml", "cache.yaml", "/abs/config.yaml"}
+
+	got := config.SearchFiles(files, paths, config.FirstMatch)
+	want := []string{
+		filepath.Join(user, "config.yaml"),
+		filepath.Join(etc, "db.yaml"),
+		filepath.Join(cwd, "cache.yaml"),
+		"/abs/config.yaml",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchFiles(FirstMatch) = %q, want %q", got, want)
+	}
+
+	got = config.SearchFiles(files, paths, config.MergeAll)
+	want = []string{
+		filepath.Join(etc, "config.yaml"),
+		filepath.Join(user, "config.yaml"),
+		filepath.Join(etc, "db.yaml"),
+		"/abs/config.yaml",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchFiles(MergeAll) = %q, want %q", got, want)
+	}
+}
+
+func TestDefaultSearchPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/me/.config")
+
+	if got := config.DefaultSearchPaths(""); !reflect.DeepEqual(got, []string{"."}) {
+		t.Errorf("DefaultSearchPaths(\"\") = %q, want only the working directory", got)
+	}
+
+	got := config.DefaultSearchPaths("orders")
+	if got[0] != "." || got[len(got)-1] != "/etc/orders" {
+		t.Errorf("DefaultSearchPaths() = %q, want working directory first and /etc/orders last", got)
+	}
+}
+
+func TestDetectConfigFile(t *testing.T) {
+	env := func(string) (string, bool) { return "/etc/orders/env.yaml", true }
+
+	if got := config.DetectConfigFile([]string{"--config", "flag.yaml"}, "ORDERS_CONFIG", env); got != "flag.yaml" {
+		t.Errorf("DetectConfigFile() = %q, want the flag", got)
+	}
+	if got := config.DetectConfigFile(nil, "ORDERS_CONFIG", env); got != "/etc/orders/env.yaml" {
+		t.Errorf("DetectConfigFile() = %q, want the env var", got)
+	}
+	if got := config.DetectConfigFile(nil, "", env); got != "" {
+		t.Errorf("DetectConfigFile() = %q, want none without env var", got)
+	}
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the value of a Secret in any output.
+const Redacted = "[REDACTED]"
+
+// Secret references of the form file:///path or env://NAME are resolved
+// by ResolveSecrets.
+const (
+	SecretFileScheme = "file://"
+	SecretEnvScheme  = "env://"
+)
+
+// Secret is a config string which redacts itself when printed, logged or
+// encoded to JSON. Use Value to read it.
+type Secret string
+
+var (
+	_ fmt.Stringer   = Secret("")
+	_ fmt.GoStringer = Secret("")
+	_ fmt.Formatter  = Secret("")
+	_ json.Marshaler = Secret("")
+	_ slog.LogValuer = Secret("")
+)
+
+// Value returns the secret value.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String returns the redacted value.
+func (s Secret) String() string {
+	return s.redacted()
+}
+
+// GoString returns the redacted value.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.redacted())
+}
+
+// Format formats the redacted value for every verb.
+func (s Secret) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'q':
+		fmt.Fprintf(f, "%q", s.redacted())
+	case 'v':
+		if f.Flag('#') {
+			fmt.Fprint(f, s.GoString())
+			return
+		}
+		fmt.Fprint(f, s.redacted())
+	default:
+		fmt.Fprint(f, s.redacted())
+	}
+}
+
+// MarshalJSON encodes the redacted value.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.redacted())
+}
+
+// LogValue logs the redacted value.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.redacted())
+}
+
+// redacted keeps empty secrets empty, so missing values remain visible.
+func (s Secret) redacted() string {
+	if s == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// ResolveSecret returns the value referenced by s, or s itself if it is not
+// a reference. Trailing newlines of files are trimmed.
+func ResolveSecret(s Secret) (Secret, error) {
+	value := string(s)
+
+	switch {
+	case strings.HasPrefix(value, SecretFileScheme):
+		path := strings.TrimPrefix(value, SecretFileScheme)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read secret file: %w", err)
+		}
+		return Secret(strings.TrimRight(string(data), "\r\n")), nil
+	case strings.HasPrefix(value, SecretEnvScheme):
+		name := strings.TrimPrefix(value, SecretEnvScheme)
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret env var %s not set", name)
+		}
+		return Secret(env), nil
+	default:
+		return s, nil
+	}
+}
+
+var secretType = reflect.TypeFor[Secret]()
+
+var errEncryptedString = errors.New("encrypted value in a string field, use config.Secret")
+
+// SecretOption is a ResolveSecrets option.
+type SecretOption func(o *secretOptions)
+
+// secretOptions is a ResolveSecrets options.
+type secretOptions struct {
+	envPrefix string
+}
+
+// WithAgeKeyEnvPrefix with the env prefix of the app, selecting the env
+// vars of the age keys, see AgeKeyEnvs.
+func WithAgeKeyEnvPrefix(prefix string) SecretOption {
+	return func(o *secretOptions) { o.envPrefix = prefix }
+}
+
+// ResolveSecrets replaces every Secret in the struct pointed to by
+// structure with its decrypted value, if encrypted, and then with the
+// referenced value. Encrypted values are only decrypted into Secret
+// fields, so that they stay redacted, and fail in string fields. Errors
+// report the field path.
+func ResolveSecrets(structure any, opts ...SecretOption) error {
+	if err := StructCheck(structure); err != nil {
+		return err
+	}
+
+	var o secretOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	v := &validation{}
+	resolveSecrets(v, &decrypter{envPrefix: o.envPrefix}, "", reflect.ValueOf(structure))
+
+	if len(v.errs) > 0 {
+		errs := make([]error, 0, len(v.errs))
+		for _, err := range v.errs {
+			errs = append(errs, err)
+		}
+		return fmt.Errorf("config: resolve secrets: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func resolveSecrets(v *validation, d *decrypter, path string, val reflect.Value) {
+	if val.Type() == secretType {
+		if !val.CanSet() {
+			return
+		}
+		secret := val.Interface().(Secret)
+		if IsEncrypted(string(secret)) {
+			plaintext, err := d.decrypt(string(secret))
+			if err != nil {
+				v.fail(path, err)
+				return
+			}
+			secret = Secret(plaintext)
+		}
+		resolved, err := ResolveSecret(secret)
+		if err != nil {
+			v.fail(path, err)
+			return
+		}
+		val.Set(reflect.ValueOf(resolved))
+		return
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		if IsEncrypted(val.String()) {
+			v.fail(path, errEncryptedString)
+		}
+	case reflect.Pointer:
+		if !val.IsNil() {
+			resolveSecrets(v, d, path, val.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			if val.Type().Field(i).IsExported() {
+				resolveSecrets(v, d, joinPath(path, val.Type().Field(i).Name), val.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			resolveSecrets(v, d, fmt.Sprintf("%s[%d]", path, i), val.Index(i))
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			// map values are not addressable, resolve a copy
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			resolveSecrets(v, d, fmt.Sprintf("%s[%v]", path, iter.Key()), elem)
+			val.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-toho/toho/config"
+)
+
+const secretValue = "hunter2"
+
+type dbConfig struct {
+	User     string
+	Password config.Secret
+}
+
+func TestSecretRedacts(t *testing.T) {
+	cfg := dbConfig{User: "toho", Password: secretValue}
+
+	var logs bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logs, nil)).Info("config", slog.Any("db", cfg), slog.Any("password", cfg.Password))
+	slog.New(slog.NewTextHandler(&logs, nil)).Info("config", slog.Any("db", cfg), slog.Any("password", cfg.Password))
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := map[string]string{
+		"String": cfg.Password.String(),
+		"%v":     fmt.Sprintf("%v", cfg),
+		"%+v":    fmt.Sprintf("%+v", cfg),
+		"%#v":    fmt.Sprintf("%#v", cfg),
+		"%s":     fmt.Sprintf("%s", cfg.Password),
+		"%q":     fmt.Sprintf("%q", cfg.Password),
+		"%x":     fmt.Sprintf("%x", cfg.Password),
+		"json":   string(data),
+		"slog":   logs.String(),
+	}
+
+	for name, out := range outputs {
+		if strings.Contains(out, secretValue) {
+			t.Errorf("%s output leaks the secret: %s", name, out)
+		}
+		if !strings.Contains(out, config.Redacted) {
+			t.Errorf("%s output is not redacted: %s", name, out)
+		}
+	}
+
+	if got := cfg.Password.Value(); got != secretValue {
+		t.Fatalf("Value() = %q, want %q", got, secretValue)
+	}
+}
+
+type secretsConfig struct {
+	DB     dbConfig
+	Token  config.Secret
+	Tokens map[string]config.Secret
+	Plain  config.Secret
+}
+
+func TestResolveSecrets(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "db")
+	if err := os.WriteFile(file, []byte(secretValue+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TOHO_TEST_TOKEN", "token-value")
+
+	cfg := &secretsConfig{
+		DB:     dbConfig{Password: config.Secret("file://" + file)},
+		Token:  "env://TOHO_TEST_TOKEN",
+		Tokens: map[string]config.Secret{"api": "env://TOHO_TEST_TOKEN"},
+		Plain:  "postgres://user:pass@db",
+	}
+
+	if err := config.ResolveSecrets(cfg); err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+
+	if got := cfg.DB.Password.Value(); got != secretValue {
+		t.Errorf("DB.Password = %q, want %q", got, secretValue)
+	}
+	if got := cfg.Token.Value(); got != "token-value" {
+		t.Errorf("Token = %q, want token-value", got)
+	}
+	if got := cfg.Tokens["api"].Value(); got != "token-value" {
+		t.Errorf("Tokens[api] = %q, want token-value", got)
+	}
+	if got := cfg.Plain.Value(); got != "postgres://user:pass@db" {
+		t.Errorf("Plain = %q, want unchanged", got)
+	}
+}
+
+func TestResolveSecretsReportsPath(t *testing.T) {
+	cfg := &secretsConfig{Token: "env://TOHO_TEST_MISSING"}
+
+	err := config.ResolveSecrets(cfg)
+	if err == nil {
+		t.Fatal("ResolveSecrets() error = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "Token:") {
+		t.Fatalf("ResolveSecrets() error = %v, want Token path", err)
+	}
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTag holds the default value of a config field.
+const defaultTag = "default"
+
+// Source is a source of config values, such as a file or the env vars.
+type Source interface {
+	// Read returns the values of the source keyed by field name. Values of
+	// sections are either nested maps, as decoded from a file, or flat
+	// keys joining the field names, like HTTP_ADDR or http.addr. Keys
+	// match field names ignoring case, '_', '-' and '.'.
+	Read() (map[string]any, error)
+
+	// Origin returns the origin of the value read at key, the dot separated
+	// keys of the nested maps holding it.
+	Origin(key string) FieldOrigin
+}
+
+// SourceLoader loads config from a list of sources, in increasing
+// precedence, on top of the `default` tags of the fields.
+type SourceLoader struct {
+	Sources []Source
+
+	mu         sync.Mutex
+	provenance Provenance
+}
+
+var (
+	_ Loader           = (*SourceLoader)(nil)
+	_ ProvenanceSource = (*SourceLoader)(nil)
+)
+
+// NewSourceLoader returns a loader reading sources, the last one taking
+// precedence.
+func NewSourceLoader(sources ...Source) *SourceLoader {
+	return &SourceLoader{Sources: sources}
+}
+
+// Load sets the fields of the struct pointed to by dst to their default,
+// then to the values of the sources, resolves its secrets with the age
+// keys of the prefix of its env source, and records the provenance of its
+// fields.
+func (l *SourceLoader) Load(dst any) error {
+	if err := StructCheck(dst); err != nil {
+		return err
+	}
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Pointer {
+		return fmt.Errorf("config: expecting pointer to struct, got %T", dst)
+	}
+
+	values := make([]map[string]any, len(l.Sources))
+	for i, source := range l.Sources {
+		v, err := source.Read()
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+
+	sl := &sourceLoading{sources: l.Sources, values: values}
+	if err := sl.section(nil, val.Elem()); err != nil {
+		return err
+	}
+	if err := ResolveSecrets(dst, WithAgeKeyEnvPrefix(l.envPrefix())); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.provenance = sl.provenance
+	return nil
+}
+
+// envPrefix returns the prefix of the first env source, which selects the
+// env vars of the age keys.
+func (l *SourceLoader) envPrefix() string {
+	for _, source := range l.Sources {
+		if env, ok := source.(*EnvSource); ok {
+			return env.Prefix
+		}
+	}
+	return ""
+}
+
+// Provenance returns the origin of every field of the last loaded config.
+func (l *SourceLoader) Provenance() Provenance {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.provenance
+}
+
+type sourceLoading struct {
+	sources    []Source
+	values     []map[string]any
+	provenance Provenance
+}
+
+func (sl *sourceLoading) section(path []string, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := append(path[:len(path):len(path)], field.Name)
+		fieldVal := val.Field(i)
+
+		if isSection(field.Type) {
+			// embedded structs are part of the parent, like aconfig reads them
+			if field.Anonymous {
+				fieldPath = path
+			}
+			if fieldVal.Kind() == reflect.Pointer {
+				if fieldVal.IsNil() {
+					fieldVal.Set(reflect.New(field.Type.Elem()))
+				}
+				fieldVal = fieldVal.Elem()
+			}
+			if err := sl.section(fieldPath, fieldVal); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := sl.leaf(fieldPath, fieldVal, field.Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sl *sourceLoading) leaf(path []string, val reflect.Value, tag reflect.StructTag) error {
+	origin := FieldOrigin{Path: strings.Join(path, ".")}
+
+	if def, ok := tag.Lookup(defaultTag); ok {
+		if err := assignString(val, def); err != nil {
+			return fmt.Errorf("config: %s: default: %w", origin.Path, err)
+		}
+		origin.Kind = OriginDefault
+	}
+
+	for i, source := range sl.sources {
+		v, key, ok := lookupKey(sl.values[i], path)
+		if !ok {
+			continue
+		}
+
+		from := source.Origin(key)
+		if err := assign(val, v); err != nil {
+			return fmt.Errorf("config: %s: %s: %w", origin.Path, from.Source(), err)
+		}
+		origin.Kind, origin.Name = from.Kind, from.Name
+	}
+
+	origin.Value = fmt.Sprint(val.Interface())
+	sl.provenance = append(sl.provenance, origin)
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// isSection reports whether values of typ are loaded field by field.
+func isSection(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct &&
+		!reflect.PointerTo(typ).Implements(textUnmarshalerType)
+}
+
+// lookupKey returns the value of the field at path in values, and the key
+// it was found at. Of the keys matching the same field, the one spelled
+// like the field names, ignoring case, wins, then the first one sorted.
+func lookupKey(values map[string]any, path []string) (any, string, bool) {
+	// the longest flat key first, then nested maps
+	for n := len(path); n > 0; n-- {
+		for _, key := range matchingKeys(values, path[:n]) {
+			v := values[key]
+			if n == len(path) {
+				return v, key, true
+			}
+			if nested, ok := v.(map[string]any); ok {
+				if v, nestedKey, ok := lookupKey(nested, path[n:]); ok {
+					return v, key + "." + nestedKey, true
+				}
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// matchingKeys returns the keys of values matching the field names, in
+// the order lookupKey tries them.
+func matchingKeys(values map[string]any, names []string) []string {
+	exact := strings.Join(names, "")
+	want := normalizeKey(exact)
+
+	var keys []string
+	for key := range values {
+		if normalizeKey(key) == want {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		if ea, eb := strings.EqualFold(a, exact), strings.EqualFold(b, exact); ea != eb {
+			if ea {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return keys
+}
+
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// assign sets val to v, a string or a value decoded from a file.
+func assign(val reflect.Value, v any) error {
+	switch v := v.(type) {
+	case nil:
+		val.SetZero()
+		return nil
+	case string:
+		return assignString(val, v)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(val.Type()) {
+		val.Set(rv)
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		if val.Kind() == reflect.String {
+			val.SetString(fmt.Sprint(v))
+			return nil
+		}
+	}
+
+	// numbers, lists and maps decoded from files
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, val.Addr().Interface())
+}
+
+// assignString sets val to the value parsed from s. Lists are comma
+// separated, and map entries are key:value pairs.
+func assignString(val reflect.Value, s string) error {
+	if val.CanAddr() {
+		if u, ok := val.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		val.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		val.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if val.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			val.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 0, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetFloat(f)
+	case reflect.Pointer:
+		ptr := reflect.New(val.Type().Elem())
+		if err := assignString(ptr.Elem(), s); err != nil {
+			return err
+		}
+		val.Set(ptr)
+	case reflect.Slice:
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			val.SetBytes([]byte(s))
+			return nil
+		}
+		items := splitList(s)
+		slice := reflect.MakeSlice(val.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assignString(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		val.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(val.Type())
+		for _, item := range splitList(s) {
+			k, v, ok := strings.Cut(item, ":")
+			if !ok {
+				return fmt.Errorf("invalid map entry %q, want key:value", item)
+			}
+			key := reflect.New(val.Type().Key()).Elem()
+			if err := assignString(key, strings.TrimSpace(k)); err != nil {
+				return err
+			}
+			elem := reflect.New(val.Type().Elem()).Elem()
+			if err := assignString(elem, strings.TrimSpace(v)); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		val.Set(m)
+	default:
+		return errors.New("unsupported type " + val.Type().String())
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	items := strings.Split(s, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
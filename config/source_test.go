@@ -0,0 +1,220 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/config"
+)
+
+type sourceHTTPConfig struct {
+	Addr        string        `default:":8080"`
+	ReadTimeout time.Duration `default:"5s"`
+}
+
+type sourceDBConfig struct {
+	DSN      string
+	Password config.Secret
+	Hosts    []string
+}
+
+type sourceConfig struct {
+	Name  string
+	Debug bool
+	Port  int `default:"80"`
+	HTTP  sourceHTTPConfig
+	DB    *sourceDBConfig
+	Tags  map[string]string
+}
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSourceLoaderMergesSources(t *testing.T) {
+	yamlFile := writeFile(t, "config.yaml", `
+name: orders
+http:
+  read_timeout: 10s
+db:
+  dsn: postgres://db
+  hosts: [a, b]
+tags:
+  team: core
+`)
+	tomlFile := writeFile(t, "config.toml", `
+port = 8000
+
+[db]
+password = "hunter2"
+`)
+	dotEnv := writeFile(t, ".env", `
+# local overrides
+export ORDERS_HTTP_ADDR=":9090"
+ORDERS_NAME="orders-local"
+OTHER_NAME=ignored
+`)
+
+	loader := config.NewSourceLoader(
+		config.File(yamlFile),
+		config.File(tomlFile),
+		config.OptionalFile(filepath.Join(t.TempDir(), "missing.json")),
+		config.DotEnv(dotEnv, "ORDERS"),
+		config.Map("overrides", map[string]any{"http": map[string]any{"addr": ":7070"}}),
+		&config.EnvSource{Prefix: "ORDERS", Environ: []string{"ORDERS_DB_HOSTS=c,d", "PATH=/bin"}},
+		&config.FlagSource{Prefix: "orders", Args: []string{"serve", "-orders.debug", "--orders.port=9000"}},
+	)
+
+	var cfg sourceConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := sourceConfig{
+		Name:  "orders-local",
+		Debug: true,
+		Port:  9000,
+		HTTP:  sourceHTTPConfig{Addr: ":7070", ReadTimeout: 10 * time.Second},
+		DB:    &sourceDBConfig{DSN: "postgres://db", Password: "hunter2", Hosts: []string{"c", "d"}},
+		Tags:  map[string]string{"team": "core"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("config = %+v, want %+v", cfg, want)
+	}
+
+	origins := map[string]string{
+		"Name":             "file " + dotEnv,
+		"Debug":            "flag -orders.debug",
+		"Port":             "flag -orders.port",
+		"HTTP.Addr":        "map overrides",
+		"HTTP.ReadTimeout": "file " + yamlFile,
+		"DB.Password":      "file " + tomlFile,
+		"DB.Hosts":         "env ORDERS_DB_HOSTS",
+	}
+	provenance := loader.Provenance()
+	for path, want := range origins {
+		origin, ok := provenance.Lookup(path)
+		if !ok || origin.Source() != want {
+			t.Errorf("origin of %s = %q, want %q", path, origin.Source(), want)
+		}
+	}
+	if origin, _ := provenance.Lookup("DB.Password"); origin.Value != config.Redacted {
+		t.Errorf("provenance value of DB.Password = %q, want redacted", origin.Value)
+	}
+}
+
+func TestSourceLoaderAppliesDefaults(t *testing.T) {
+	loader := config.NewSourceLoader()
+
+	var cfg sourceConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Port != 80 || cfg.HTTP.Addr != ":8080" || cfg.HTTP.ReadTimeout != 5*time.Second {
+		t.Fatalf("config = %+v, want defaults", cfg)
+	}
+	if origin, _ := loader.Provenance().Lookup("Name"); origin.Kind != "" {
+		t.Errorf("origin of Name = %+v, want unset", origin)
+	}
+}
+
+func TestSourceLoaderReportsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source config.Source
+		want   string
+	}{
+		{
+			name:   "invalid value",
+			source: config.Map("test", map[string]any{"port": "eighty"}),
+			want:   "config: Port: map test:",
+		},
+		{
+			name:   "missing file",
+			source: config.File(filepath.Join(t.TempDir(), "config.json")),
+			want:   "no such file",
+		},
+		{
+			name:   "unknown extension",
+			source: config.File(writeFile(t, "config.ini", "port=1")),
+			want:   `no decoder for ".ini" files`,
+		},
+		{
+			name:   "invalid file",
+			source: config.File(writeFile(t, "config.json", "{")),
+			want:   "config: decode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg sourceConfig
+			err := config.NewSourceLoader(tt.source).Load(&cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+type SourceSamplingRule struct {
+	First int `default:"100"`
+}
+
+type sourceSamplingConfig struct {
+	SourceSamplingRule
+	Enabled bool
+}
+
+type sourceEmbeddingConfig struct {
+	Sampling sourceSamplingConfig
+}
+
+func TestSourceLoaderFlattensEmbeddedStructs(t *testing.T) {
+	loader := config.NewSourceLoader(
+		config.Map("test", map[string]any{"sampling": map[string]any{"first": 9}}),
+	)
+
+	var cfg sourceEmbeddingConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Sampling.First != 9 {
+		t.Fatalf("Sampling.First = %d, want 9", cfg.Sampling.First)
+	}
+	if origin, _ := loader.Provenance().Lookup("Sampling.First"); origin.Name != "test" {
+		t.Errorf("origin of Sampling.First = %+v, want map test", origin)
+	}
+}
+
+func TestSourceLoaderPrefersKeysSpelledLikeFields(t *testing.T) {
+	loader := config.NewSourceLoader(config.Map("test", map[string]any{
+		"http": map[string]any{
+			"read-timeout": "1s",
+			"read_timeout": "2s",
+			"readtimeout":  "3s",
+		},
+	}))
+
+	for i := 0; i < 20; i++ {
+		var cfg sourceConfig
+		if err := loader.Load(&cfg); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.HTTP.ReadTimeout != 3*time.Second {
+			t.Fatalf("HTTP.ReadTimeout = %s, want 3s", cfg.HTTP.ReadTimeout)
+		}
+	}
+}
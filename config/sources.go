@@ -0,0 +1,269 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OriginMap is the origin kind of values set by a MapSource.
+const OriginMap = "map"
+
+// EnvSource reads env vars, like APP_HTTP_ADDR for the field HTTP.Addr
+// with the prefix APP.
+type EnvSource struct {
+	Prefix string
+
+	// Environ lists the env vars as KEY=value, os.Environ() if nil.
+	Environ []string
+}
+
+// Env returns a source of the env vars starting with prefix and '_',
+// or of every env var if prefix is empty.
+func Env(prefix string) *EnvSource {
+	return &EnvSource{Prefix: prefix}
+}
+
+func (s *EnvSource) Read() (map[string]any, error) {
+	environ := s.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+
+	values := make(map[string]any)
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if key, ok := trimKeyPrefix(key, s.Prefix, "_"); ok {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func (s *EnvSource) Origin(key string) FieldOrigin {
+	return FieldOrigin{Kind: OriginEnv, Name: joinKeyPrefix(s.Prefix, "_", key)}
+}
+
+// FlagSource reads command line flags, like -app.http.addr=:8080 for the
+// field HTTP.Addr with the prefix app. A flag without a value is true, and
+// a flag followed by an argument not starting with '-' takes it as value.
+// Other arguments are ignored.
+type FlagSource struct {
+	Prefix string
+
+	// Args are the command line arguments, os.Args[1:] if nil.
+	Args []string
+}
+
+// Flags returns a source of the flags starting with prefix and '.',
+// or of every flag if prefix is empty.
+func Flags(prefix string) *FlagSource {
+	return &FlagSource{Prefix: prefix}
+}
+
+func (s *FlagSource) Read() (map[string]any, error) {
+	args := s.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+
+	values := make(map[string]any)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !hasValue {
+			value = "true"
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				i++
+				value = args[i]
+			}
+		}
+
+		if key, ok := trimKeyPrefix(name, s.Prefix, "."); ok {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func (s *FlagSource) Origin(key string) FieldOrigin {
+	return FieldOrigin{Kind: OriginFlag, Name: "-" + joinKeyPrefix(s.Prefix, ".", key)}
+}
+
+// MapSource reads values from a map, nested or with flat keys.
+type MapSource struct {
+	Name   string
+	Values map[string]any
+}
+
+// Map returns a source of values, named name in the provenance.
+func Map(name string, values map[string]any) *MapSource {
+	return &MapSource{Name: name, Values: values}
+}
+
+func (s *MapSource) Read() (map[string]any, error) {
+	return s.Values, nil
+}
+
+func (s *MapSource) Origin(string) FieldOrigin {
+	return FieldOrigin{Kind: OriginMap, Name: s.Name}
+}
+
+// FileSource reads a config file with a decoder.
+type FileSource struct {
+	Path string
+
+	// Decoder decodes the file, by default the decoder registered for the
+	// file extension.
+	Decoder Decoder
+
+	// Optional ignores the file if it does not exist.
+	Optional bool
+}
+
+// File returns a source of the file at path, decoded according to its
+// extension.
+func File(path string) *FileSource {
+	return &FileSource{Path: path}
+}
+
+// OptionalFile is like File, ignoring the file if it does not exist.
+func OptionalFile(path string) *FileSource {
+	return &FileSource{Path: path, Optional: true}
+}
+
+func (s *FileSource) Read() (map[string]any, error) {
+	data, err := readSourceFile(s.Path, s.Optional)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	decoder := s.Decoder
+	if decoder == nil {
+		if decoder, err = DecoderFor(s.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	values, err := decoder.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("config: decode %s: %w", s.Path, err)
+	}
+	return values, nil
+}
+
+func (s *FileSource) Origin(string) FieldOrigin {
+	return FieldOrigin{Kind: OriginFile, Name: s.Path}
+}
+
+// DotEnvSource reads a .env file of KEY=value lines, like EnvSource.
+// Blank lines and lines starting with '#' are ignored, as is an export
+// keyword before the key. Values may be single or double quoted.
+type DotEnvSource struct {
+	Path   string
+	Prefix string
+
+	// Optional ignores the file if it does not exist.
+	Optional bool
+}
+
+// DotEnv returns a source of the env vars starting with prefix and '_'
+// set in the file at path.
+func DotEnv(path, prefix string) *DotEnvSource {
+	return &DotEnvSource{Path: path, Prefix: prefix}
+}
+
+func (s *DotEnvSource) Read() (map[string]any, error) {
+	data, err := readSourceFile(s.Path, s.Optional)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	environ, err := parseDotEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("config: decode %s: %w", s.Path, err)
+	}
+	return (&EnvSource{Prefix: s.Prefix, Environ: environ}).Read()
+}
+
+func (s *DotEnvSource) Origin(string) FieldOrigin {
+	return FieldOrigin{Kind: OriginFile, Name: s.Path}
+}
+
+func parseDotEnv(data []byte) ([]string, error) {
+	var environ []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing '='", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+
+		environ = append(environ, key+"="+value)
+	}
+	return environ, scanner.Err()
+}
+
+// readSourceFile returns the content of the file at path, or nil if it
+// does not exist and is optional.
+func readSourceFile(path string, optional bool) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if optional && errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+func trimKeyPrefix(key, prefix, sep string) (string, bool) {
+	if prefix == "" {
+		return key, true
+	}
+	return strings.CutPrefix(key, prefix+sep)
+}
+
+func joinKeyPrefix(prefix, sep, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + sep + key
+}
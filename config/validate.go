@@ -0,0 +1,273 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validateTag is the struct tag holding the validation rules of a field.
+//
+// Rules are separated by commas:
+//
+//	required    the value must not be the zero value
+//	min=N       numbers must be at least N, strings, slices and maps must
+//	            have at least N elements
+//	max=N       like min, as an upper bound
+//	oneof=A B   the value must be one of the space separated values
+//
+// Durations accept N in time.ParseDuration format. Rules other than
+// required are skipped for zero values, and check the value pointed to by
+// pointers.
+const validateTag = "validate"
+
+// Validator is implemented by config structs validating themselves.
+// It is called for the config and for every nested section.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError is a validation error of a config field.
+type FieldError struct {
+	// Path is the dot separated path of the field, like "HTTP.Addr".
+	// It is empty for errors of the config itself.
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError aggregates the errors of a config validation.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return "config: validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Validate checks that structure is a non-nil struct, then validates the
+// `validate` tags of its fields and calls Validate on every section
+// implementing Validator. All errors are reported in a *ValidationError.
+func Validate(structure any) error {
+	if err := StructCheck(structure); err != nil {
+		return err
+	}
+
+	v := &validation{}
+	v.value("", reflect.ValueOf(structure))
+
+	if len(v.errs) > 0 {
+		return &ValidationError{Errors: v.errs}
+	}
+	return nil
+}
+
+type validation struct {
+	errs []*FieldError
+}
+
+func (v *validation) fail(path string, err error) {
+	v.errs = append(v.errs, &FieldError{Path: path, Err: err})
+}
+
+func (v *validation) value(path string, val reflect.Value) {
+	switch val.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if val.IsNil() {
+			return
+		}
+		v.value(path, val.Elem())
+	case reflect.Struct:
+		v.section(path, val)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			v.value(fmt.Sprintf("%s[%d]", path, i), val.Index(i))
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			v.value(fmt.Sprintf("%s[%v]", path, iter.Key()), iter.Value())
+		}
+	}
+}
+
+func (v *validation) section(path string, val reflect.Value) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := joinPath(path, field.Name)
+
+		if rules, ok := field.Tag.Lookup(validateTag); ok {
+			for _, err := range checkRules(val.Field(i), rules) {
+				v.fail(fieldPath, err)
+			}
+		}
+
+		v.value(fieldPath, val.Field(i))
+	}
+
+	if validator, ok := asValidator(val); ok {
+		if err := validator.Validate(); err != nil {
+			v.failValidator(path, err)
+		}
+	}
+}
+
+// failValidator records the error of a Validator, resolving the paths of
+// field errors relative to the section.
+func (v *validation) failValidator(path string, err error) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		for _, fe := range verr.Errors {
+			v.fail(joinPath(path, fe.Path), fe.Err)
+		}
+		return
+	}
+
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		v.fail(joinPath(path, fe.Path), fe.Err)
+		return
+	}
+
+	v.fail(path, err)
+}
+
+func joinPath(path, name string) string {
+	switch {
+	case path == "":
+		return name
+	case name == "":
+		return path
+	default:
+		return path + "." + name
+	}
+}
+
+func asValidator(val reflect.Value) (Validator, bool) {
+	if val.CanAddr() {
+		if validator, ok := val.Addr().Interface().(Validator); ok {
+			return validator, true
+		}
+	}
+	if val.CanInterface() {
+		validator, ok := val.Interface().(Validator)
+		return validator, ok
+	}
+	return nil, false
+}
+
+func checkRules(val reflect.Value, rules string) []error {
+	var errs []error
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name == "" {
+			continue
+		}
+
+		if name == "required" {
+			if val.IsZero() {
+				errs = append(errs, errors.New("required"))
+			}
+			continue
+		}
+
+		if val.IsZero() {
+			continue
+		}
+
+		if err := checkRule(reflect.Indirect(val), name, arg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func checkRule(val reflect.Value, name, arg string) error {
+	switch name {
+	case "min", "max":
+		limit, err := parseLimit(val, arg)
+		if err != nil {
+			return fmt.Errorf("invalid %s rule: %w", name, err)
+		}
+
+		size, ok := sizeOf(val)
+		if !ok {
+			return fmt.Errorf("%s rule unsupported for %s", name, val.Type())
+		}
+
+		if name == "min" && size < limit {
+			return fmt.Errorf("must be at least %s", arg)
+		}
+		if name == "max" && size > limit {
+			return fmt.Errorf("must be at most %s", arg)
+		}
+		return nil
+	case "oneof":
+		options := strings.Fields(arg)
+		got := fmt.Sprint(val.Interface())
+		for _, option := range options {
+			if got == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of [%s], got %q", strings.Join(options, " "), got)
+	default:
+		return fmt.Errorf("unknown validation rule %q", name)
+	}
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+func parseLimit(val reflect.Value, arg string) (float64, error) {
+	if val.Type() == durationType {
+		d, err := time.ParseDuration(arg)
+		return float64(d), err
+	}
+	return strconv.ParseFloat(arg, 64)
+}
+
+// sizeOf returns the value of numbers and the length of collections.
+func sizeOf(val reflect.Value) (float64, bool) {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(val.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), true
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(val.Len()), true
+	default:
+		return 0, false
+	}
+}
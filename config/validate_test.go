@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/config"
+)
+
+type serverConfig struct {
+	Addr    string        `validate:"required"`
+	Workers int           `validate:"min=1,max=8"`
+	Timeout time.Duration `validate:"max=1m"`
+	Format  string        `validate:"oneof=json text"`
+}
+
+type poolConfig struct {
+	Size int
+}
+
+func (c poolConfig) Validate() error {
+	if c.Size > 10 {
+		return errors.New("size too large")
+	}
+	return nil
+}
+
+type appConfig struct {
+	HTTP  serverConfig
+	Admin *serverConfig
+	Pools map[string]poolConfig
+	Tags  []string `validate:"min=1"`
+}
+
+func validationPaths(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error = %v, want *config.ValidationError", err)
+	}
+
+	paths := make(map[string]string)
+	for _, fe := range verr.Errors {
+		paths[fe.Path] = fe.Err.Error()
+	}
+	return paths
+}
+
+func TestValidateAcceptsValidConfig(t *testing.T) {
+	cfg := &appConfig{
+		HTTP:  serverConfig{Addr: ":8080", Workers: 4, Timeout: time.Second, Format: "json"},
+		Pools: map[string]poolConfig{"primary": {Size: 5}},
+		Tags:  []string{"a"},
+	}
+
+	if err := config.Validate(cfg); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+}
+
+func TestValidateReportsFieldPaths(t *testing.T) {
+	cfg := &appConfig{
+		HTTP:  serverConfig{Workers: 9, Timeout: time.Hour, Format: "xml"},
+		Admin: &serverConfig{Addr: ":9090", Workers: 1},
+		Pools: map[string]poolConfig{"primary": {Size: 11}},
+		Tags:  []string{},
+	}
+
+	paths := validationPaths(t, config.Validate(cfg))
+
+	for path, want := range map[string]string{
+		"HTTP.Addr":      "required",
+		"HTTP.Workers":   "must be at most 8",
+		"HTTP.Timeout":   "must be at most 1m",
+		"HTTP.Format":    "must be one of",
+		"Pools[primary]": "size too large",
+		"Tags":           "must be at least 1",
+	} {
+		got, ok := paths[path]
+		if !ok {
+			t.Errorf("no error for %s, got %v", path, paths)
+			continue
+		}
+		if !strings.Contains(got, want) {
+			t.Errorf("%s error = %q, want %q", path, got, want)
+		}
+	}
+
+	if _, ok := paths["Admin.Addr"]; ok {
+		t.Errorf("unexpected error for Admin.Addr: %v", paths)
+	}
+	if _, ok := paths["Admin.Format"]; ok {
+		t.Errorf("zero value Admin.Format validated, want skipped: %v", paths)
+	}
+}
+
+func TestValidateRejectsNonStruct(t *testing.T) {
+	if err := config.Validate("toho"); err == nil {
+		t.Fatal("Validate() error = nil, want error")
+	}
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Value holds the current value of a config, or of one of its sections.
+// Load is lock-free and returns a consistent snapshot, Store swaps it
+// atomically. A reload swaps it when Value is subscribed to a Reloader.
+//
+// Sections shared through pointers, maps or slices must not be modified,
+// a reload replaces them instead.
+type Value[T any] struct {
+	ptr atomic.Pointer[T]
+
+	mu     sync.Mutex // guards subs and orders stores
+	subs   []valueSub[T]
+	nextID int
+	stores notifyQueue[valueStore[T]]
+}
+
+type valueStore[T any] struct {
+	old, new T
+}
+
+type valueSub[T any] struct {
+	id int
+	fn func(old, new T)
+}
+
+// verify that Value implements the Subscriber interface.
+var _ Subscriber = (*Value[struct{}])(nil)
+
+// NewValue returns a value holding v.
+func NewValue[T any](v T) *Value[T] {
+	value := &Value[T]{}
+	value.ptr.Store(&v)
+	return value
+}
+
+// SectionValue returns a value holding the section of type T of the config
+// pointed to by current, or the config itself if it is of type T. The
+// section type must be used once in the config, and a section behind a
+// nil pointer is the zero value.
+func SectionValue[T any](current any) (*Value[T], error) {
+	root := reflect.ValueOf(current)
+	if root.Kind() != reflect.Pointer || root.IsNil() || root.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: expecting pointer to struct, got %T", current)
+	}
+
+	section, err := sectionOf[T](root.Elem())
+	if err != nil {
+		return nil, err
+	}
+	return NewValue(section), nil
+}
+
+// sectionOf returns the section of type T of the config root, which must
+// be used once in it.
+func sectionOf[T any](root reflect.Value) (T, error) {
+	var zero T
+	typ := reflect.TypeFor[T]()
+
+	var found []reflect.Value
+	findSections(root, typ, map[reflect.Type]bool{}, &found)
+	switch len(found) {
+	case 0:
+		return zero, fmt.Errorf("config: no section of type %s in %s", typ, root.Type())
+	case 1:
+	default:
+		return zero, fmt.Errorf("config: section type %s is used %d times in %s", typ, len(found), root.Type())
+	}
+
+	if !found[0].IsValid() {
+		return zero, nil
+	}
+	return found[0].Interface().(T), nil
+}
+
+// findSections appends the sections of v of type typ, walking the sections
+// Reloader notifies about. Sections behind nil pointers are invalid values.
+func findSections(v reflect.Value, typ reflect.Type, parents map[reflect.Type]bool, found *[]reflect.Value) {
+	if v.Type() == typ {
+		*found = append(*found, v)
+	}
+
+	structType := v.Type()
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+		if structType == typ {
+			if v.IsNil() {
+				*found = append(*found, reflect.Value{})
+			} else {
+				*found = append(*found, v.Elem())
+			}
+		}
+	}
+	if structType.Kind() != reflect.Struct || parents[structType] {
+		return
+	}
+	parents[structType] = true
+	defer delete(parents, structType)
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v = reflect.Zero(structType)
+		} else {
+			v = v.Elem()
+		}
+	}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct ||
+			(field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct) {
+			findSections(v.Field(i), typ, parents, found)
+		}
+	}
+}
+
+// Load returns the current value.
+func (v *Value[T]) Load() T {
+	return *v.ptr.Load()
+}
+
+// Store swaps the current value with new and notifies the subscribers, in
+// the order they subscribed. Subscribers may subscribe, cancel or store,
+// and see the stores in order: a store made by a subscriber or while
+// another one is notifying returns once swapped, and that one notifies it
+// next.
+func (v *Value[T]) Store(new T) {
+	v.mu.Lock()
+	old := v.ptr.Swap(&new)
+	v.stores.push(valueStore[T]{old: *old, new: new})
+	v.mu.Unlock()
+
+	v.stores.drain(v.notify)
+}
+
+// notify notifies the subscribers of a store.
+func (v *Value[T]) notify(s valueStore[T]) {
+	v.mu.Lock()
+	subs := slices.Clone(v.subs)
+	v.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.fn(s.old, s.new)
+	}
+}
+
+// Subscribe registers fn, called with the old and new value after every
+// store, and returns a function removing it.
+func (v *Value[T]) Subscribe(fn func(old, new T)) (cancel func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	id := v.nextID
+	v.nextID++
+	v.subs = append(v.subs, valueSub[T]{id: id, fn: fn})
+
+	return func() {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+
+		v.subs = slices.DeleteFunc(v.subs, func(sub valueSub[T]) bool { return sub.id == id })
+	}
+}
+
+// Type implements Subscriber, watching the sections of type T.
+func (v *Value[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// Notify implements Subscriber, storing the new section.
+func (v *Value[T]) Notify(_, new any) {
+	v.Store(new.(T))
+}
+
+// storeSection stores the section of type T of the config root, if it
+// changed.
+func (v *Value[T]) storeSection(root reflect.Value) {
+	section, err := sectionOf[T](root)
+	if err != nil || reflect.DeepEqual(section, v.Load()) {
+		return
+	}
+	v.Store(section)
+}
+
+// sectionValue is a Value of any type.
+type sectionValue interface {
+	storeSection(root reflect.Value)
+}
+
+// Values holds a Value for the root config and for every section of it,
+// created by ValueOf on first use. Subscribed to a Reloader, it stores the
+// changed sections in their values on reloads.
+type Values struct {
+	mu      sync.Mutex // guards current and values
+	current reflect.Value
+	values  map[reflect.Type]sectionValue
+}
+
+// verify that Values implements the Subscriber interface.
+var _ Subscriber = (*Values)(nil)
+
+// NewValues returns the values of the config pointed to by current.
+func NewValues(current any) (*Values, error) {
+	root := reflect.ValueOf(current)
+	if root.Kind() != reflect.Pointer || root.IsNil() || root.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: expecting pointer to struct, got %T", current)
+	}
+	return &Values{
+		current: root.Elem(),
+		values:  make(map[reflect.Type]sectionValue),
+	}, nil
+}
+
+// ValueOf returns the value of the section of type T of values, or of the
+// root config if it is of type T, see SectionValue. Every call for T
+// returns the same value.
+func ValueOf[T any](values *Values) (*Value[T], error) {
+	typ := reflect.TypeFor[T]()
+
+	values.mu.Lock()
+	defer values.mu.Unlock()
+
+	if v, ok := values.values[typ]; ok {
+		return v.(*Value[T]), nil
+	}
+
+	section, err := sectionOf[T](values.current)
+	if err != nil {
+		return nil, err
+	}
+	v := NewValue(section)
+	values.values[typ] = v
+	return v, nil
+}
+
+// Type implements Subscriber, watching the root config.
+func (v *Values) Type() reflect.Type {
+	return v.current.Type()
+}
+
+// Notify implements Subscriber, storing the changed sections of the new
+// config in their values.
+func (v *Values) Notify(_, new any) {
+	v.mu.Lock()
+	v.current = reflect.ValueOf(new)
+	root := v.current
+	values := make([]sectionValue, 0, len(v.values))
+	for _, value := range v.values {
+		values = append(values, value)
+	}
+	v.mu.Unlock()
+
+	for _, value := range values {
+		value.storeSection(root)
+	}
+}
@@ -0,0 +1,225 @@
+package config_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/config"
+)
+
+func TestValueStoreNotifiesSubscribers(t *testing.T) {
+	v := config.NewValue(LogConfig{Level: "info"})
+
+	var calls []string
+	cancel := v.Subscribe(func(old, new LogConfig) {
+		calls = append(calls, old.Level+"->"+new.Level)
+	})
+
+	v.Store(LogConfig{Level: "debug"})
+	if got := v.Load().Level; got != "debug" {
+		t.Fatalf("Load().Level = %q, want debug", got)
+	}
+
+	cancel()
+	v.Store(LogConfig{Level: "warn"})
+
+	if len(calls) != 1 || calls[0] != "info->debug" {
+		t.Fatalf("subscriber calls = %v, want [info->debug]", calls)
+	}
+}
+
+func TestValueSubscribersMayCancelDuringStore(t *testing.T) {
+	v := config.NewValue(LogConfig{Level: "info"})
+
+	var (
+		calls  []string
+		cancel func()
+	)
+	cancel = v.Subscribe(func(_, new LogConfig) {
+		calls = append(calls, "first:"+new.Level)
+		cancel()
+		v.Subscribe(func(_, new LogConfig) {
+			calls = append(calls, "added:"+new.Level)
+		})
+	})
+	v.Subscribe(func(_, new LogConfig) {
+		calls = append(calls, "second:"+new.Level)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v.Store(LogConfig{Level: "debug"})
+		v.Store(LogConfig{Level: "warn"})
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Store() blocked on a subscriber canceling")
+	}
+
+	want := "first:debug second:debug second:warn added:warn"
+	if got := strings.Join(calls, " "); got != want {
+		t.Fatalf("subscriber calls = %s, want %s", got, want)
+	}
+}
+
+func TestValueNotifiesInOrderWhenSubscriberStores(t *testing.T) {
+	v := config.NewValue(LogConfig{Level: "info"})
+
+	v.Subscribe(func(_, new LogConfig) {
+		if new.Level == "debug" {
+			v.Store(LogConfig{Level: "warn"})
+		}
+	})
+
+	var calls []string
+	v.Subscribe(func(old, new LogConfig) {
+		calls = append(calls, old.Level+"->"+new.Level)
+	})
+
+	v.Store(LogConfig{Level: "debug"})
+
+	want := "info->debug debug->warn"
+	if got := strings.Join(calls, " "); got != want {
+		t.Fatalf("second subscriber calls = %s, want %s", got, want)
+	}
+	if got := v.Load().Level; got != "warn" {
+		t.Fatalf("Load().Level = %q, want warn", got)
+	}
+}
+
+func TestSectionValueFollowsReloads(t *testing.T) {
+	current := &RootConfig{Log: LogConfig{Level: "info"}}
+	src := &RootConfig{Log: LogConfig{Level: "debug"}}
+
+	r, err := config.NewReloader(current, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := config.SectionValue[LogConfig](r.Current())
+	if err != nil {
+		t.Fatalf("SectionValue() error = %v", err)
+	}
+	root, err := config.SectionValue[RootConfig](r.Current())
+	if err != nil {
+		t.Fatalf("SectionValue() error = %v", err)
+	}
+	r.Subscribe(log)
+	r.Subscribe(root)
+
+	if got := log.Load().Level; got != "info" {
+		t.Fatalf("Load().Level = %q before reload, want info", got)
+	}
+
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if got := log.Load().Level; got != "debug" {
+		t.Errorf("section Load().Level = %q, want debug", got)
+	}
+	if got := root.Load().Log.Level; got != "debug" {
+		t.Errorf("root Load().Log.Level = %q, want debug", got)
+	}
+}
+
+func TestSectionValueNilPointerSection(t *testing.T) {
+	v, err := config.SectionValue[*HTTPConfig](&RootConfig{})
+	if err != nil {
+		t.Fatalf("SectionValue() error = %v", err)
+	}
+	if got := v.Load(); got != nil {
+		t.Fatalf("Load() = %+v, want nil", got)
+	}
+}
+
+func TestSectionValueRejectsMissingOrAmbiguousSections(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func() error
+		want string
+	}{
+		{
+			name: "missing",
+			fn: func() error {
+				_, err := config.SectionValue[LogConfig](&HTTPConfig{})
+				return err
+			},
+			want: "no section of type config_test.LogConfig",
+		},
+		{
+			name: "ambiguous",
+			fn: func() error {
+				// HTTP and DB are both HTTPConfig sections
+				_, err := config.SectionValue[HTTPConfig](&RootConfig{})
+				return err
+			},
+			want: "is used 2 times",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fn()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("SectionValue() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValuesFollowReloads(t *testing.T) {
+	current := &RootConfig{Log: LogConfig{Level: "info"}}
+	src := &RootConfig{Log: LogConfig{Level: "debug"}, DB: &HTTPConfig{Addr: ":5432"}}
+
+	r, err := config.NewReloader(current, loadFrom(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := config.NewValues(r.Current())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Subscribe(values)
+
+	log, err := config.ValueOf[LogConfig](values)
+	if err != nil {
+		t.Fatalf("ValueOf() error = %v", err)
+	}
+	db, err := config.ValueOf[*HTTPConfig](values)
+	if err != nil {
+		t.Fatalf("ValueOf() error = %v", err)
+	}
+	if again, _ := config.ValueOf[LogConfig](values); again != log {
+		t.Fatal("ValueOf() returned distinct values for the same type")
+	}
+	if _, err := config.ValueOf[HTTPConfig](values); err == nil {
+		t.Fatal("ValueOf() error = nil for an ambiguous section type")
+	}
+
+	var notified int
+	log.Subscribe(func(_, _ LogConfig) { notified++ })
+
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := log.Load().Level; got != "debug" {
+		t.Errorf("section Load().Level = %q, want debug", got)
+	}
+	if got := db.Load(); got == nil || got.Addr != ":5432" {
+		t.Errorf("section Load() = %+v, want :5432", got)
+	}
+
+	// unchanged sections are not stored again
+	src.DB = &HTTPConfig{Addr: ":5433"}
+	if err := r.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if notified != 1 {
+		t.Errorf("section notified %d times, want 1", notified)
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+)
+
+// Subscriber is notified about config sections changed by a reload.
+type Subscriber interface {
+	// Type returns the type of the config section the subscriber watches.
+	Type() reflect.Type
+
+	// Notify is called with the old and new value of a changed section.
+	Notify(old, new any)
+}
+
+// Watch returns a subscriber calling fn whenever a section of type T, or
+// the whole config if it is of type T, changed during a reload.
+func Watch[T any](fn func(old, new T)) Subscriber {
+	return watcher[T](fn)
+}
+
+type watcher[T any] func(old, new T)
+
+func (w watcher[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (w watcher[T]) Notify(old, new any) {
+	w(old.(T), new.(T))
+}
+
+// section is a pair of values of the same config section.
+type section struct {
+	old, new reflect.Value
+}
+
+// changedSections returns the sections of old and new which differ, keyed
+// by their type. The whole config is a section too.
+func changedSections(old, new reflect.Value) map[reflect.Type][]section {
+	changes := make(map[reflect.Type][]section)
+	appendChangedSections(changes, old, new)
+	return changes
+}
+
+func appendChangedSections(changes map[reflect.Type][]section, old, new reflect.Value) {
+	if old.Kind() == reflect.Pointer {
+		if old.IsNil() || new.IsNil() {
+			if old.IsNil() != new.IsNil() {
+				changes[old.Type()] = append(changes[old.Type()], section{old: old, new: new})
+			}
+			return
+		}
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			changes[old.Type()] = append(changes[old.Type()], section{old: old, new: new})
+		}
+		old, new = old.Elem(), new.Elem()
+	}
+
+	if old.Kind() != reflect.Struct {
+		return
+	}
+
+	if reflect.DeepEqual(old.Interface(), new.Interface()) {
+		return
+	}
+	changes[old.Type()] = append(changes[old.Type()], section{old: old, new: new})
+
+	for i := 0; i < old.NumField(); i++ {
+		if !old.Type().Field(i).IsExported() {
+			continue
+		}
+
+		field := old.Field(i)
+		if field.Kind() == reflect.Struct ||
+			(field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct) {
+			appendChangedSections(changes, field, new.Field(i))
+		}
+	}
+}
@@ -17,6 +17,9 @@ var Module = fx.Module("aconfig",
 	provideConfigLoader,
 	provideConfigLoaderFlags,
 	provideConfig,
+	provideLoadFunc,
+	provideProvenanceSource,
+	provideFileLister,
 )
 
 var (
@@ -24,15 +27,26 @@ var (
 		fx.Annotate(
 			func(
 				appName string,
-				files []string,
-				config aconfig.Config,
+				profile string,
+				files []config.Files,
+				paths []string,
+				searchPaths []string,
+				searchPolicy config.SearchPolicy,
+				aconfigConfig aconfig.Config,
 				walkFn func(f aconfig.Field) bool,
 				fileDecoders []aconfig.FileDecoder,
 			) *aconfigo.Loader {
+				if searchPaths == nil {
+					searchPaths = config.DefaultSearchPaths(appName)
+				}
+
 				loader := aconfigo.NewLoader().
 					WithAppName(appName).
-					WithFiles(files).
-					WithConfig(config).
+					WithProfile(profile).
+					WithFiles(config.OrderFiles(append(files, config.Files{Paths: paths}))).
+					WithSearchPaths(searchPaths).
+					WithSearchPolicy(searchPolicy).
+					WithConfig(aconfigConfig).
 					WithWalkFn(walkFn)
 
 				for _, decoder := range fileDecoders {
@@ -43,7 +57,11 @@ var (
 			},
 			fx.ParamTags(
 				fxtags.NamedOptional(app.NamedAppName),
+				fxtags.NamedOptional(config.NamedProfile),
+				fxtags.Group(config.GroupConfigFiles),
 				fxtags.Group(config.GroupConfigFiles),
+				fxtags.NamedOptional(config.NamedSearchPaths),
+				fxtags.NamedOptional(config.NamedSearchPolicy),
 				fxtags.NamedOptional(aconfigo.NamedConfig),
 				fxtags.NamedOptional(aconfigo.NamedWalkFn),
 				fxtags.Group(aconfigo.GroupFileDecoders),
@@ -74,19 +92,41 @@ var (
 
 	provideConfig = fx.Provide(
 		fx.Annotate(
-			func(aloader *aconfig.Loader, cfg any) (any, error) {
-				if err := aloader.Load(); err != nil {
+			func(loader *aconfigo.Loader, aloader *aconfig.Loader, cfg any) (any, error) {
+				if err := loader.LoadWith(aloader, cfg); err != nil {
 					return nil, err
 				}
 				return cfg, nil
 			},
 			fx.ParamTags(
+				fxtags.Empty,
 				fxtags.Empty,
 				fxtags.Named(config.NamedConfigPointerIn),
 			),
 			fx.ResultTags(fxtags.Named(config.NamedConfigPointerOut)),
 		),
 	)
+
+	provideLoadFunc = fx.Provide(
+		fx.Annotate(
+			func(loader *aconfigo.Loader) config.LoadFunc {
+				return loader.Load
+			},
+			fx.ResultTags(fxtags.Named(config.NamedConfigLoadFunc)),
+		),
+	)
+
+	provideProvenanceSource = fx.Provide(
+		func(loader *aconfigo.Loader) config.ProvenanceSource {
+			return loader
+		},
+	)
+
+	provideFileLister = fx.Provide(
+		func(loader *aconfigo.Loader) config.FileLister {
+			return loader
+		},
+	)
 )
 
 func SupplyConfig(config aconfig.Config) fx.Option {
@@ -111,6 +151,18 @@ func SupplyWalkFn(fn func(f aconfig.Field) bool) fx.Option {
 	)
 }
 
+// Decoder modules, reading the config files with their extension.
+var (
+	// YAML reads .yaml and .yml files.
+	YAML = fx.Module("aconfig.yaml", SupplyFileDecoder(aconfigo.YAMLDecoder()))
+
+	// TOML reads .toml files.
+	TOML = fx.Module("aconfig.toml", SupplyFileDecoder(aconfigo.TOMLDecoder()))
+
+	// HCL reads .hcl files.
+	HCL = fx.Module("aconfig.hcl", SupplyFileDecoder(aconfigo.HCLDecoder()))
+)
+
 func SupplyFileDecoder(decoder aconfig.FileDecoder) fx.Option {
 	return fx.Provide(
 		fx.Annotate(
@@ -0,0 +1,37 @@
+package aconfigofx
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/config/configfx"
+	"github.com/go-toho/toho/contrib/config/aconfigo"
+	"github.com/go-toho/toho/pkg/fxtags"
+)
+
+func TestLoaderReadsPlainSuppliedFiles(t *testing.T) {
+	var loader *aconfigo.Loader
+	app := fx.New(
+		fx.NopLogger,
+		provideLoader,
+		configfx.SupplyConfigFile("/etc/orders/app.yaml"),
+		fx.Provide(
+			fx.Annotate(
+				func() string { return "/etc/orders/legacy.yaml" },
+				fx.ResultTags(fxtags.Group(config.GroupConfigFiles)),
+			),
+		),
+		fx.Populate(&loader),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("expected app to build, got error: %v", err)
+	}
+
+	want := []string{"/etc/orders/legacy.yaml", "/etc/orders/app.yaml"}
+	if got := loader.ConfigFiles(); !slices.Equal(got, want) {
+		t.Fatalf("ConfigFiles() = %v, want %v", got, want)
+	}
+}
@@ -2,19 +2,41 @@ package aconfigo
 
 import (
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/cristalhq/aconfig"
+
+	"github.com/go-toho/toho/config"
 )
 
 type Loader struct {
 	AppName string
 	Files   []string
 
+	// Profile layers the profile and local overlays of Files on top of
+	// them, see config.LayerFiles, and applies the profile defaults.
+	Profile string
+
+	// SearchPaths are the directories searched for the relative Files, in
+	// decreasing precedence, see config.DefaultSearchPaths. SearchPolicy
+	// selects the files loaded when found in several of them: with
+	// config.MergeAll they are merged, with config.FirstMatch the files
+	// are merged only if Config.MergeFiles is set, like without search.
+	SearchPaths  []string
+	SearchPolicy config.SearchPolicy
+
 	Config       aconfig.Config
 	WalkFn       func(f aconfig.Field) bool
 	FileDecoders map[string]aconfig.FileDecoder
+
+	mu                sync.Mutex
+	provenance        config.Provenance
+	pendingProvenance func() config.Provenance
 }
 
 func NewLoader() *Loader {
@@ -28,6 +50,21 @@ func (l *Loader) WithAppName(appName string) *Loader {
 	return l
 }
 
+func (l *Loader) WithProfile(profile string) *Loader {
+	l.Profile = profile
+	return l
+}
+
+func (l *Loader) WithSearchPaths(paths []string) *Loader {
+	l.SearchPaths = paths
+	return l
+}
+
+func (l *Loader) WithSearchPolicy(policy config.SearchPolicy) *Loader {
+	l.SearchPolicy = policy
+	return l
+}
+
 func (l *Loader) WithFiles(files []string) *Loader {
 	l.Files = files
 	return l
@@ -55,10 +92,30 @@ func (l *Loader) WithFileDecoder(decoder aconfig.FileDecoder) *Loader {
 }
 
 func (l *Loader) For(cfg any) *aconfig.Loader {
-	// remove duplicate files
-	slices.Sort(l.Files)
-	l.Files = slices.Compact(l.Files)
+	c := l.aconfigConfig()
+	if c.EnvPrefix != "" && c.FileFlag == "" {
+		// the file env var is not a field
+		c.Envs = withoutEnv(c.Envs, c.EnvPrefix+config.ConfigFileEnvSuffix)
+	}
+	loader := aconfig.LoaderFor(cfg, c)
+
+	// the profile and files are selected before loading, accept their flags
+	if !c.SkipFlags && loader.Flags().Lookup(config.ProfileFlag) == nil {
+		loader.Flags().String(config.ProfileFlag, l.Profile, "config profile")
+	}
+	if !c.SkipFlags && c.FileFlag == "" && loader.Flags().Lookup(config.ConfigFileFlag) == nil {
+		loader.Flags().String(config.ConfigFileFlag, "", "config file, instead of the searched ones")
+	}
 
+	if l.WalkFn != nil {
+		loader.WalkFields(l.WalkFn)
+	}
+
+	return loader
+}
+
+// aconfigConfig returns the aconfig config derived from the loader settings.
+func (l *Loader) aconfigConfig() aconfig.Config {
 	c := l.Config
 
 	if c.EnvPrefix == "" && l.AppName != "" {
@@ -73,19 +130,169 @@ func (l *Loader) For(cfg any) *aconfig.Loader {
 		c.FlagPrefix = strings.TrimSpace(c.FlagPrefix)
 	}
 
-	if len(l.Files) > 0 {
-		c.Files = append(c.Files, l.Files...)
+	// files are listed in increasing precedence, keep their order
+	files := slices.Concat(c.Files, l.Files)
+	if file := l.configFile(c); file != "" {
+		files = []string{file}
+	} else if len(l.SearchPaths) > 0 {
+		files = config.SearchFiles(files, l.SearchPaths, l.SearchPolicy)
 	}
 
-	if len(l.FileDecoders) > 0 {
-		c.FileDecoders = l.FileDecoders
+	// merging is up to the config, unless layering the files found in
+	// several search paths or the profile overlays
+	c.Files = config.LayerFiles(files, l.Profile)
+	if l.Profile != "" || (len(l.SearchPaths) > 0 && l.SearchPolicy == config.MergeAll) {
+		c.MergeFiles = true
 	}
 
-	loader := aconfig.LoaderFor(cfg, c)
+	c.FileDecoders = fileDecoders(c.FileDecoders, l.FileDecoders)
 
-	if l.WalkFn != nil {
-		loader.WalkFields(l.WalkFn)
+	return c
+}
+
+// configFile returns the config file set by the --config flag or the
+// <APP>_CONFIG env var, unless aconfig handles the file flag itself.
+func (l *Loader) configFile(c aconfig.Config) string {
+	if c.FileFlag != "" {
+		return ""
 	}
 
-	return loader
+	args := c.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+	if c.SkipFlags {
+		args = nil
+	}
+
+	env := ""
+	if c.EnvPrefix != "" && !c.SkipEnv {
+		env = c.EnvPrefix + config.ConfigFileEnvSuffix
+	}
+
+	return config.DetectConfigFile(args, env, func(name string) (string, bool) {
+		if c.Envs == nil {
+			return os.LookupEnv(name)
+		}
+		for _, kv := range c.Envs {
+			if k, v, _ := strings.Cut(kv, "="); k == name {
+				return v, true
+			}
+		}
+		return "", false
+	})
+}
+
+// withoutEnv returns envs, or os.Environ() if nil, without the env var name.
+func withoutEnv(envs []string, name string) []string {
+	if envs == nil {
+		envs = os.Environ()
+	}
+	return slices.DeleteFunc(slices.Clone(envs), func(kv string) bool {
+		return strings.HasPrefix(kv, name+"=")
+	})
+}
+
+// extAliases maps file extensions to the extension of their decoder.
+var extAliases = map[string]string{
+	".yml": ".yaml",
+}
+
+// fileDecoders merges the decoders by extension, adding the extension
+// aliases of the decoders.
+func fileDecoders(decoders ...map[string]aconfig.FileDecoder) map[string]aconfig.FileDecoder {
+	merged := make(map[string]aconfig.FileDecoder)
+	for _, d := range decoders {
+		maps.Copy(merged, d)
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+
+	for alias, ext := range extAliases {
+		if _, ok := merged[alias]; !ok && merged[ext] != nil {
+			merged[alias] = merged[ext]
+		}
+	}
+	return merged
+}
+
+// checkFileFormats returns an error for the first file no decoder reads,
+// which aconfig reports only if the file exists.
+func checkFileFormats(c aconfig.Config) error {
+	for _, file := range c.Files {
+		ext := strings.ToLower(filepath.Ext(file))
+		if _, ok := c.FileDecoders[ext]; ok || ext == ".json" {
+			continue
+		}
+		return fmt.Errorf("aconfigo: no decoder for %q files like %s, "+
+			"add one with WithFileDecoder or the aconfigofx.YAML, TOML and HCL modules", ext, file)
+	}
+	return nil
+}
+
+// ConfigFiles returns the files read by Load: the selected config file,
+// or the files found in the search paths, with their overlays.
+func (l *Loader) ConfigFiles() []string {
+	return l.aconfigConfig().Files
+}
+
+// Load loads cfg, resolves its secret references and records the
+// provenance of its fields.
+func (l *Loader) Load(cfg any) error {
+	return l.LoadWith(l.For(cfg), cfg)
+}
+
+// LoadWith is like Load, using aloader created by For(cfg).
+func (l *Loader) LoadWith(aloader *aconfig.Loader, cfg any) error {
+	c := l.aconfigConfig()
+	if err := checkFileFormats(c); err != nil {
+		return err
+	}
+	if file := l.configFile(c); file != "" {
+		// unlike searched files, the selected one must exist
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("aconfigo: config file: %w", err)
+		}
+	}
+	if err := aloader.Load(); err != nil {
+		return err
+	}
+	if err := config.ResolveSecrets(cfg, config.WithAgeKeyEnvPrefix(c.EnvPrefix)); err != nil {
+		return err
+	}
+
+	envs := l.fieldEnvs(aloader)
+	var profiled config.Provenance
+	if l.Profile != "" {
+		set := l.sourceFields(aloader, cfg, envs)
+		var err error
+		profiled, err = config.ApplyProfileDefaultsFunc(cfg, l.Profile, func(path string) bool { return set[path] })
+		if err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// replaying the sources is costly, only done if asked
+	l.provenance = nil
+	l.pendingProvenance = func() config.Provenance {
+		return l.provenanceOf(aloader, cfg, envs, profiled)
+	}
+	return nil
+}
+
+// Provenance returns the origin of every field of the last loaded config,
+// computed on the first call after a load.
+func (l *Loader) Provenance() config.Provenance {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pendingProvenance != nil {
+		l.provenance = l.pendingProvenance()
+		l.pendingProvenance = nil
+	}
+	return l.provenance
 }
@@ -0,0 +1,239 @@
+package aconfigo_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/cristalhq/aconfig"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/contrib/config/aconfigo"
+	"github.com/go-toho/toho/logger"
+)
+
+func TestLoaderLayersProfileOverlays(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.json":       `{"name": "orders", "port": 8000, "http": {"addr": ":80"}}`,
+		"config.prod.json":  `{"port": 9000, "http": {"addr": ":443"}}`,
+		"config.local.json": `{"http": {"addr": ":8443"}}`,
+		"config.dev.json":   `{"name": "dev"}`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base := filepath.Join(dir, "config.json")
+	loader := aconfigo.NewLoader().
+		WithAppName("orders").
+		WithProfile(config.ProfileProd).
+		WithFile(base).
+		WithConfig(aconfig.Config{Envs: []string{}, Args: []string{}})
+
+	cfg := &appConfig{}
+	if err := loader.Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Name != "orders" || cfg.Port != 9000 || cfg.HTTP.Addr != ":8443" {
+		t.Errorf("config = %+v, want name from base, port from prod, addr from local", cfg)
+	}
+
+	origin, _ := loader.Provenance().Lookup("HTTP.Addr")
+	if want := filepath.Join(dir, "config.local.json"); origin.Name != want {
+		t.Errorf("origin of HTTP.Addr = %+v, want %s", origin, want)
+	}
+}
+
+func TestLoaderDecodesFileFormats(t *testing.T) {
+	tests := []struct {
+		file    string
+		data    string
+		decoder aconfig.FileDecoder
+	}{
+		{"config.yaml", "name: orders\nhttp:\n  addr: \":80\"\n", aconfigo.YAMLDecoder()},
+		{"config.yml", "name: orders\nhttp:\n  addr: \":80\"\n", aconfigo.YAMLDecoder()},
+		{"config.toml", "name = \"orders\"\n[http]\naddr = \":80\"\n", aconfigo.TOMLDecoder()},
+		{"config.hcl", "name = \"orders\"\nhttp {\n  addr = \":80\"\n}\n", aconfigo.HCLDecoder()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(file, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			loader := aconfigo.NewLoader().
+				WithFile(file).
+				WithFileDecoder(tt.decoder).
+				WithConfig(aconfig.Config{Envs: []string{}, Args: []string{}})
+
+			cfg := &appConfig{}
+			if err := loader.Load(cfg); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Name != "orders" || cfg.HTTP.Addr != ":80" {
+				t.Fatalf("config = %+v, want name and addr from %s", cfg, tt.file)
+			}
+		})
+	}
+}
+
+func TestLoaderRejectsUnknownFileFormat(t *testing.T) {
+	// the file does not exist, aconfig would skip it
+	loader := aconfigo.NewLoader().
+		WithFile(filepath.Join(t.TempDir(), "config.yaml")).
+		WithConfig(aconfig.Config{Envs: []string{}, Args: []string{}})
+
+	err := loader.Load(&appConfig{})
+	if err == nil || !strings.Contains(err.Error(), `no decoder for ".yaml" files`) {
+		t.Fatalf("Load() error = %v, want unknown format error", err)
+	}
+}
+
+func TestLoaderSearchesFiles(t *testing.T) {
+	cwd, etc := t.TempDir(), t.TempDir()
+	for file, data := range map[string]string{
+		filepath.Join(cwd, "config.json"): `{"port": 9000}`,
+		filepath.Join(etc, "config.json"): `{"name": "orders", "port": 8000}`,
+	} {
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newLoader := func(policy config.SearchPolicy, envs ...string) *aconfigo.Loader {
+		return aconfigo.NewLoader().
+			WithAppName("orders").
+			WithFile("config.json").
+			WithSearchPaths([]string{cwd, etc}).
+			WithSearchPolicy(policy).
+			WithConfig(aconfig.Config{Envs: envs, Args: []string{}})
+	}
+
+	cfg := &appConfig{}
+	if err := newLoader(config.FirstMatch).Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Name != "" || cfg.Port != 9000 {
+		t.Errorf("first match config = %+v, want the working directory file only", cfg)
+	}
+
+	cfg = &appConfig{}
+	if err := newLoader(config.MergeAll).Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Name != "orders" || cfg.Port != 9000 {
+		t.Errorf("merged config = %+v, want both files, the working directory one first", cfg)
+	}
+
+	cfg = &appConfig{}
+	override := filepath.Join(etc, "config.json")
+	if err := newLoader(config.FirstMatch, "ORDERS_CONFIG="+override).Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Port != 8000 {
+		t.Errorf("overridden config = %+v, want %s only", cfg, override)
+	}
+
+	missing := filepath.Join(etc, "missing.json")
+	if err := newLoader(config.FirstMatch, "ORDERS_CONFIG="+missing).Load(&appConfig{}); err == nil {
+		t.Errorf("Load() error = nil, want missing config file error")
+	}
+}
+
+func TestLoaderMergesSearchedFilesOnRequest(t *testing.T) {
+	dir := t.TempDir()
+	for file, data := range map[string]string{
+		"base.json": `{"name": "orders", "port": 8000}`,
+		"app.json":  `{"port": 9000}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, merge := range []bool{false, true} {
+		loader := aconfigo.NewLoader().
+			WithFiles([]string{"base.json", "app.json"}).
+			WithSearchPaths([]string{dir}).
+			WithConfig(aconfig.Config{Envs: []string{}, Args: []string{}, MergeFiles: merge})
+
+		cfg := &appConfig{}
+		if err := loader.Load(cfg); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+
+		want := appConfig{Name: "orders", Port: 8000}
+		if merge {
+			want.Port = 9000
+		}
+		if cfg.Name != want.Name || cfg.Port != want.Port {
+			t.Errorf("config with MergeFiles %v = %+v, want %+v", merge, cfg, want)
+		}
+	}
+}
+
+func TestLoaderDecryptsValues(t *testing.T) {
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyEnv, _ := config.AgeKeyEnvs("ORDERS")
+	t.Setenv(keyEnv, key.String())
+
+	password, err := config.Encrypt("hunter2", key.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, []byte(`{"db": {"password": "`+password+`"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := aconfigo.NewLoader().
+		WithAppName("orders").
+		WithFile(file).
+		WithConfig(aconfig.Config{Envs: []string{}, Args: []string{}})
+
+	cfg := &appConfig{}
+	if err := loader.Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.DB.Password.Value(); got != "hunter2" {
+		t.Fatalf("DB.Password = %q, want the decrypted hunter2", got)
+	}
+	var out bytes.Buffer
+	if err := loader.Provenance().WriteText(&out, true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Fatalf("provenance leaks the decrypted value:\n%s", out.String())
+	}
+}
+
+func TestLoaderValidatesDefaultedLoggerConfig(t *testing.T) {
+	loader := aconfigo.NewLoader().
+		WithConfig(aconfig.Config{Envs: []string{}, Args: []string{}, SkipFiles: true})
+
+	cfg := &struct {
+		Logger logger.Config `default:"{}"`
+	}{}
+	if err := loader.Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := config.Validate(cfg); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if cfg.Logger.Async.Overflow != logger.BlockOverflow {
+		t.Errorf("Async.Overflow = %q, want the default %s", cfg.Logger.Async.Overflow, logger.BlockOverflow)
+	}
+}
@@ -0,0 +1,86 @@
+package aconfigo
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cristalhq/aconfig"
+	"github.com/hashicorp/hcl"
+
+	"github.com/go-toho/toho/config"
+)
+
+// HCL decodes HCL files. Blocks are decoded as nested maps.
+var HCL config.Decoder = config.DecoderFunc(func(data []byte) (map[string]any, error) {
+	values := make(map[string]any)
+	if err := hcl.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return flattenBlocks(values).(map[string]any), nil
+})
+
+// flattenBlocks replaces the single element lists HCL decodes blocks to
+// with their element.
+func flattenBlocks(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			v[k] = flattenBlocks(value)
+		}
+		return v
+	case []map[string]any:
+		if len(v) == 1 {
+			return flattenBlocks(v[0])
+		}
+		list := make([]any, len(v))
+		for i, value := range v {
+			list[i] = flattenBlocks(value)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// YAMLDecoder returns the decoder of .yaml and .yml files.
+func YAMLDecoder() aconfig.FileDecoder {
+	return FileDecoder("yaml", config.YAMLDecoder)
+}
+
+// TOMLDecoder returns the decoder of .toml files.
+func TOMLDecoder() aconfig.FileDecoder {
+	return FileDecoder("toml", config.TOMLDecoder)
+}
+
+// HCLDecoder returns the decoder of .hcl files.
+func HCLDecoder() aconfig.FileDecoder {
+	return FileDecoder("hcl", HCL)
+}
+
+// FileDecoder adapts decoder to aconfig, for the files with extension
+// .format.
+func FileDecoder(format string, decoder config.Decoder) aconfig.FileDecoder {
+	return &fileDecoder{format: format, decoder: decoder}
+}
+
+type fileDecoder struct {
+	format  string
+	decoder config.Decoder
+}
+
+func (d *fileDecoder) Format() string {
+	return d.format
+}
+
+func (d *fileDecoder) DecodeFile(filename string) (map[string]any, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := d.decoder.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", filename, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,365 @@
+package aconfigo
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/cristalhq/aconfig"
+
+	"github.com/go-toho/toho/config"
+)
+
+// verify that Loader implements the config.ProvenanceSource and
+// config.FieldNamer interfaces.
+var (
+	_ config.ProvenanceSource = (*Loader)(nil)
+	_ config.FieldNamer       = (*Loader)(nil)
+)
+
+// provenanceOf replays the sources of aloader with aconfig, in the order
+// it applies them: defaults, files, env vars and flags, one file, env var
+// and flag at a time. The last source setting a field is its origin. envs
+// are the env vars of the fields, and profiled the origins of the fields
+// set to their profile default.
+func (l *Loader) provenanceOf(aloader *aconfig.Loader, cfg any, envs []string, profiled config.Provenance) config.Provenance {
+	c := l.aconfigConfig()
+	typ := reflect.TypeOf(cfg).Elem()
+
+	origins := map[string]config.FieldOrigin{}
+	replay := func(kind, name string, rc aconfig.Config) {
+		for path := range setFields(typ, rc, aloader.Flags()) {
+			origins[path] = config.FieldOrigin{Kind: kind, Name: name}
+		}
+	}
+
+	if !c.SkipFiles {
+		for _, file := range loadedFiles(c, aloader.Flags()) {
+			rc := replayConfig(c)
+			rc.SkipFiles, rc.Files = false, []string{file}
+			replay(config.OriginFile, file, rc)
+		}
+	}
+
+	if !c.SkipEnv {
+		for _, env := range envs {
+			name, _, _ := strings.Cut(env, "=")
+			rc := replayConfig(c)
+			rc.SkipEnv, rc.Envs = false, []string{env}
+			replay(config.OriginEnv, name, rc)
+		}
+	}
+
+	if !c.SkipFlags {
+		aloader.Flags().Visit(func(f *flag.Flag) {
+			rc := replayConfig(c)
+			rc.SkipFlags, rc.Args = false, []string{"-" + f.Name + "=" + f.Value.String()}
+			replay(config.OriginFlag, "-"+f.Name, rc)
+		})
+	}
+
+	var provenance config.Provenance
+	aloader.WalkFields(func(f aconfig.Field) bool {
+		origin, ok := origins[f.Name()]
+		if !ok && f.Tag("default") != "" && !c.SkipDefaults {
+			// aconfig counts a field with a default as set
+			origin.Kind = config.OriginDefault
+		}
+		origin.Path = f.Name()
+		origin.Value = fieldValue(cfg, f.Name())
+
+		provenance = append(provenance, origin)
+		return true
+	})
+
+	return provenance.Replace(profiled...)
+}
+
+// sourceFields returns the paths of the fields of cfg set by the sources
+// of aloader, defaults excluded, replaying them all at once.
+func (l *Loader) sourceFields(aloader *aconfig.Loader, cfg any, envs []string) map[string]bool {
+	c := l.aconfigConfig()
+
+	rc := replayConfig(c)
+	if !c.SkipFiles {
+		rc.SkipFiles, rc.Files, rc.MergeFiles = false, loadedFiles(c, aloader.Flags()), true
+	}
+	if !c.SkipEnv {
+		rc.SkipEnv, rc.Envs = false, envs
+	}
+	if !c.SkipFlags {
+		rc.SkipFlags = false
+		aloader.Flags().Visit(func(f *flag.Flag) {
+			rc.Args = append(rc.Args, "-"+f.Name+"="+f.Value.String())
+		})
+	}
+	return setFields(reflect.TypeOf(cfg).Elem(), rc, aloader.Flags())
+}
+
+// fieldEnvs returns the env vars aconfig reads for the fields of aloader.
+func (l *Loader) fieldEnvs(aloader *aconfig.Loader) []string {
+	c := l.aconfigConfig()
+	if c.SkipEnv {
+		return nil
+	}
+
+	envPrefix, _, _ := namePrefixes(c)
+	names := map[string]bool{}
+	aloader.WalkFields(func(f aconfig.Field) bool {
+		if name := fullTag(f, "env", "_"); name != "" {
+			names[envPrefix+name] = true
+		}
+		return true
+	})
+
+	envs := c.Envs
+	if envs == nil {
+		envs = os.Environ()
+	}
+	var fieldEnvs []string
+	for _, env := range envs {
+		if name, _, _ := strings.Cut(env, "="); names[name] {
+			fieldEnvs = append(fieldEnvs, env)
+		}
+	}
+	return fieldEnvs
+}
+
+// replayConfig returns c skipping every source, accepting the unknown
+// fields, env vars and flags of the sources replayed one by one.
+func replayConfig(c aconfig.Config) aconfig.Config {
+	c.SkipDefaults, c.SkipFiles, c.SkipEnv, c.SkipFlags = true, true, true, true
+	c.Files, c.Envs, c.Args = nil, []string{}, []string{}
+	c.FileFlag, c.MergeFiles, c.FailOnFileNotFound = "", false, false
+	c.AllowUnknownFields, c.AllowUnknownEnvs, c.AllowUnknownFlags = true, true, true
+	return c
+}
+
+// setFields returns the paths of the fields of a config of type typ that
+// aconfig sets loading with c, flags being the flags defined besides the
+// fields. A field set to its zero value is found by loading again a config
+// with a non-zero value for it.
+func setFields(typ reflect.Type, c aconfig.Config, flags *flag.FlagSet) map[string]bool {
+	load := func(dst reflect.Value) []aconfig.Field {
+		loader := aconfig.LoaderFor(dst.Interface(), c)
+		flags.VisitAll(func(f *flag.Flag) {
+			if loader.Flags().Lookup(f.Name) == nil {
+				loader.Flags().String(f.Name, "", f.Usage)
+			}
+		})
+		if err := loader.Load(); err != nil {
+			return nil
+		}
+
+		var fields []aconfig.Field
+		loader.WalkFields(func(f aconfig.Field) bool {
+			fields = append(fields, f)
+			return true
+		})
+		return fields
+	}
+
+	set := map[string]bool{}
+	var zeros []string
+	zero := reflect.New(typ)
+	for _, f := range load(zero) {
+		if v, ok := fieldByPath(zero, f.Name()); ok && !v.IsZero() {
+			set[f.Name()] = true
+		} else {
+			zeros = append(zeros, f.Name())
+		}
+	}
+	if len(zeros) == 0 {
+		return set
+	}
+
+	perturbed := reflect.New(typ)
+	before := map[string]any{}
+	for _, path := range zeros {
+		if v, ok := fieldByPath(perturbed, path); ok && perturb(v) {
+			before[path] = v.Interface()
+		}
+	}
+	if load(perturbed) == nil {
+		return set
+	}
+	for path, value := range before {
+		if v, ok := fieldByPath(perturbed, path); ok && !reflect.DeepEqual(v.Interface(), value) {
+			set[path] = true
+		}
+	}
+	return set
+}
+
+// fieldByPath returns the field at the dot separated path of the struct v
+// points to, allocating the nil pointers on the way.
+func fieldByPath(v reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	return v, v.CanInterface()
+}
+
+// perturb sets v to a value other than its zero value, if it is a basic
+// value or a pointer to one. aconfig never leaves the other kinds nil when
+// setting them.
+func perturb(v reflect.Value) bool {
+	if !v.CanSet() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		return perturb(v.Elem())
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.String:
+		v.SetString("-")
+	default:
+		return false
+	}
+	return true
+}
+
+// FieldNames returns the env vars and flags setting the fields of cfg,
+// prefixed like For derives from the app name.
+func (l *Loader) FieldNames(cfg any) map[string]config.FieldNames {
+	c := l.aconfigConfig()
+	envPrefix, flagPrefix, flagDelimiter := namePrefixes(c)
+
+	names := make(map[string]config.FieldNames)
+	l.For(cfg).WalkFields(func(f aconfig.Field) bool {
+		var n config.FieldNames
+		if name := fullTag(f, "env", "_"); name != "" && !c.SkipEnv {
+			n.Env = envPrefix + name
+		}
+		if name := fullTag(f, "flag", flagDelimiter); name != "" && !c.SkipFlags {
+			n.Flag = "-" + flagPrefix + name
+		}
+		names[f.Name()] = n
+		return true
+	})
+	return names
+}
+
+// namePrefixes returns the prefixes of the env vars and flags, with their
+// delimiter, and the flag delimiter.
+func namePrefixes(c aconfig.Config) (envPrefix, flagPrefix, flagDelimiter string) {
+	if c.EnvPrefix != "" {
+		envPrefix = c.EnvPrefix + "_"
+	}
+	flagDelimiter = c.FlagDelimiter
+	if flagDelimiter == "" {
+		flagDelimiter = "."
+	}
+	if c.FlagPrefix != "" {
+		flagPrefix = c.FlagPrefix + flagDelimiter
+	}
+	return envPrefix, flagPrefix, flagDelimiter
+}
+
+// loadedFiles returns the files aconfig loads with c: the existing ones,
+// or only the first one unless MergeFiles is set.
+func loadedFiles(c aconfig.Config, flags *flag.FlagSet) []string {
+	files := c.Files
+	if c.FileFlag != "" {
+		if f := flags.Lookup(c.FileFlag); f != nil && f.Value.String() != "" {
+			if c.MergeFiles {
+				files = append(slices.Clip(files), f.Value.String())
+			} else {
+				files = []string{f.Value.String()}
+			}
+		}
+	}
+
+	var loaded []string
+	for _, file := range files {
+		if _, err := statFile(c, file); err != nil {
+			continue
+		}
+		loaded = append(loaded, file)
+
+		if !c.MergeFiles {
+			break
+		}
+	}
+	return loaded
+}
+
+func statFile(c aconfig.Config, file string) (fs.FileInfo, error) {
+	if c.FileSystem != nil {
+		return fs.Stat(c.FileSystem, file)
+	}
+	return os.Stat(file)
+}
+
+// fullTag returns the name of f for tag, joined with the names of its
+// parents, as aconfig does.
+func fullTag(f aconfig.Field, tag, sep string) string {
+	res := f.Tag(tag)
+	if res == "-" {
+		return ""
+	}
+	if before, _, ok := strings.Cut(res, ",exact"); ok {
+		return before
+	}
+	if before, _, ok := strings.Cut(res, ",omitempty"); ok {
+		return before
+	}
+	for p, ok := f.Parent(); ok; p, ok = p.Parent() {
+		if p.Tag(tag) != "-" {
+			res = p.Tag(tag) + sep + res
+		}
+	}
+	return res
+}
+
+// fieldValue returns the printed value of the field at path in cfg.
+func fieldValue(cfg any, path string) string {
+	v := reflect.ValueOf(cfg)
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return ""
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return ""
+		}
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			return ""
+		}
+	}
+
+	if !v.CanInterface() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
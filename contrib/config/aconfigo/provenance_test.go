@@ -0,0 +1,157 @@
+package aconfigo_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/cristalhq/aconfig"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/contrib/config/aconfigo"
+)
+
+type httpConfig struct {
+	Addr    string `default:":8080"`
+	Timeout string `default:"5s"`
+}
+
+type dbConfig struct {
+	DSN      string
+	Password config.Secret
+}
+
+type appConfig struct {
+	Name string
+	HTTP httpConfig
+	DB   dbConfig
+	Port int
+}
+
+func TestLoaderRecordsProvenance(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	data := `{"name": "orders", "db": {"dsn": "postgres://db", "password": "hunter2"}}`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := aconfigo.NewLoader().
+		WithAppName("orders").
+		WithFile(file).
+		WithConfig(aconfig.Config{
+			Envs: []string{"ORDERS_HTTP_TIMEOUT=10s"},
+			Args: []string{"-orders.port=9000"},
+		})
+
+	cfg := &appConfig{}
+	if err := loader.Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]config.FieldOrigin{
+		"Name":         {Value: "orders", Kind: config.OriginFile, Name: file},
+		"HTTP.Addr":    {Value: ":8080", Kind: config.OriginDefault},
+		"HTTP.Timeout": {Value: "10s", Kind: config.OriginEnv, Name: "ORDERS_HTTP_TIMEOUT"},
+		"DB.DSN":       {Value: "postgres://db", Kind: config.OriginFile, Name: file},
+		"DB.Password":  {Value: config.Redacted, Kind: config.OriginFile, Name: file},
+		"Port":         {Value: "9000", Kind: config.OriginFlag, Name: "-orders.port"},
+	}
+
+	provenance := loader.Provenance()
+	if len(provenance) != len(want) {
+		t.Fatalf("Provenance() = %+v, want %d fields", provenance, len(want))
+	}
+
+	for path, w := range want {
+		got, ok := provenance.Lookup(path)
+		if !ok {
+			t.Errorf("no origin for %s", path)
+			continue
+		}
+		w.Path = path
+		if got != w {
+			t.Errorf("origin of %s = %+v, want %+v", path, got, w)
+		}
+	}
+}
+
+func TestLoaderRecordsProvenanceOfZeroValues(t *testing.T) {
+	type debugConfig struct {
+		Enabled bool `default:"true"`
+		Port    int  `default:"6060"`
+	}
+	type zeroConfig struct {
+		Debug debugConfig
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, []byte(`{"debug": {"enabled": false}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := aconfigo.NewLoader().
+		WithAppName("orders").
+		WithFile(file).
+		WithConfig(aconfig.Config{
+			Envs: []string{"ORDERS_DEBUG_PORT=0"},
+			Args: []string{},
+		})
+
+	cfg := &zeroConfig{}
+	if err := loader.Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]config.FieldOrigin{
+		"Debug.Enabled": {Path: "Debug.Enabled", Value: "false", Kind: config.OriginFile, Name: file},
+		"Debug.Port":    {Path: "Debug.Port", Value: "0", Kind: config.OriginEnv, Name: "ORDERS_DEBUG_PORT"},
+	}
+	for path, w := range want {
+		if got, _ := loader.Provenance().Lookup(path); got != w {
+			t.Errorf("origin of %s = %+v, want %+v", path, got, w)
+		}
+	}
+}
+
+type profiledLogConfig struct {
+	Level  string `default:"info"`
+	Format string
+}
+
+func (profiledLogConfig) ProfileDefault(profile string) any {
+	if profile == config.ProfileLocal {
+		return profiledLogConfig{Level: "debug", Format: "text"}
+	}
+	return nil
+}
+
+type profiledConfig struct {
+	Log profiledLogConfig
+}
+
+func TestLoaderRecordsProvenanceOfProfileDefaults(t *testing.T) {
+	loader := aconfigo.NewLoader().
+		WithProfile(config.ProfileLocal).
+		WithConfig(aconfig.Config{
+			Envs: []string{"PATH=/bin", "LOG_FORMAT=json", "HOME=/root"},
+			Args: []string{},
+		})
+
+	cfg := &profiledConfig{}
+	if err := loader.Load(cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Log.Level != "debug" || cfg.Log.Format != "json" {
+		t.Fatalf("Log = %+v, want the debug profile level and the json env format", cfg.Log)
+	}
+
+	want := config.Provenance{
+		{Path: "Log.Level", Value: "debug", Kind: config.OriginProfile, Name: config.ProfileLocal},
+		{Path: "Log.Format", Value: "json", Kind: config.OriginEnv, Name: "LOG_FORMAT"},
+	}
+	if got := loader.Provenance(); !slices.Equal(got, want) {
+		t.Fatalf("Provenance() = %+v, want %+v", got, want)
+	}
+}
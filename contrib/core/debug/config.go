@@ -0,0 +1,32 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-toho/toho/config"
+)
+
+// ConfigPattern is the pattern of the config provenance endpoint.
+const ConfigPattern = "/debug/config"
+
+// NewConfigHandler returns a handler serving the provenance of the loaded
+// config, as a text table or as JSON with ?format=json. Secret values are
+// redacted.
+func NewConfigHandler(source config.ProvenanceSource) Handler {
+	return Handler{
+		Pattern: ConfigPattern,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provenance := source.Provenance()
+
+			if r.URL.Query().Get("format") == "json" {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(provenance)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_ = provenance.WriteText(w, true)
+		}),
+	}
+}
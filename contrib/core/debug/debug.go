@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	_ "net/http/pprof" // Enables pprof endpoint.
+	"net/http/pprof"
+
+	"github.com/go-toho/toho/config"
 )
 
 type Config struct {
@@ -12,7 +14,49 @@ type Config struct {
 	Addr    string `default:":6060"`
 }
 
-func NewHTTPServer(config Config) (*http.Server, error) {
+// Validate checks the address of an enabled server.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		return &config.FieldError{Path: "Addr", Err: err}
+	}
+	return nil
+}
+
+// Handler is an HTTP handler mounted on the debug server.
+type Handler struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// NewServeMux returns a mux serving the pprof endpoints and handlers, and
+// falling back to http.DefaultServeMux, like expvar's /debug/vars, unless
+// a handler is mounted on "/".
+func NewServeMux(handlers ...Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	fallback := true
+	for _, h := range handlers {
+		if h.Handler != nil {
+			mux.Handle(h.Pattern, h.Handler)
+			fallback = fallback && h.Pattern != "/"
+		}
+	}
+	if fallback {
+		mux.Handle("/", http.DefaultServeMux)
+	}
+
+	return mux
+}
+
+func NewHTTPServer(config Config, handlers ...Handler) (*http.Server, error) {
 	host, port, err := net.SplitHostPort(config.Addr)
 	if err != nil {
 		return nil, fmt.Errorf("could not resolve address: %v", err)
@@ -22,7 +66,10 @@ func NewHTTPServer(config Config) (*http.Server, error) {
 		host = "localhost"
 	}
 
-	server := &http.Server{Addr: fmt.Sprintf("%s:%s", host, port)}
+	server := &http.Server{
+		Addr:    fmt.Sprintf("%s:%s", host, port),
+		Handler: NewServeMux(handlers...),
+	}
 
 	return server, nil
 }
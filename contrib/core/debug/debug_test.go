@@ -0,0 +1,32 @@
+package debug_test
+
+import (
+	_ "expvar"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-toho/toho/contrib/core/debug"
+)
+
+func TestServeMuxFallsBackToDefaultServeMux(t *testing.T) {
+	mux := debug.NewServeMux(debug.Handler{
+		Pattern: "/debug/custom",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("custom"))
+		}),
+	})
+
+	tests := map[string]string{
+		"/debug/vars":   "memstats",
+		"/debug/custom": "custom",
+	}
+	for path, want := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("GET %s = %d %q, want %q", path, rec.Code, rec.Body.String(), want)
+		}
+	}
+}
@@ -4,25 +4,37 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	"go.uber.org/fx"
 
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/config/configfx"
 	"github.com/go-toho/toho/contrib/core/debug"
 	"github.com/go-toho/toho/pkg/fxtags"
 )
 
+// reconfigureTimeout bounds the shutdown of the old server when the config
+// changes.
+const reconfigureTimeout = 5 * time.Second
+
 var Module = fx.Module("debug",
+	configfx.SupplyKey(config.KeyFor[debug.Config](debug.ConfigKey)),
 	provideConfigPointer,
 	provideConfig,
+	provideServer,
+	provideSubscriber,
+	provideConfigHandler,
 	invokeServer,
 )
 
 var (
 	provideConfigPointer = fx.Provide(
 		fx.Annotate(
-			func(config any) *debug.Config {
-				if config != nil {
-					switch v := config.(type) {
+			func(cfg any, root any) *debug.Config {
+				if cfg != nil {
+					switch v := cfg.(type) {
 					case *debug.Config:
 						return v
 					case debug.Config:
@@ -31,9 +43,15 @@ var (
 						break
 					}
 				}
+				if v, ok := config.BindSection[debug.Config](root, debug.ConfigKey); ok {
+					return v
+				}
 				return &debug.Config{}
 			},
-			fx.ParamTags(fxtags.NamedOptional(debug.NamedConfig)),
+			fx.ParamTags(
+				fxtags.NamedOptional(debug.NamedConfig),
+				fxtags.NamedOptional(config.NamedConfigPointerOut),
+			),
 			fx.ResultTags(fxtags.Named(debug.NamedConfig)),
 		),
 	)
@@ -47,42 +65,153 @@ var (
 		),
 	)
 
-	invokeServer = fx.Invoke(NewDebugServer)
+	provideServer = fx.Provide(
+		fx.Annotate(
+			NewDebugServer,
+			fx.ParamTags(
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Group(debug.GroupHandlers),
+			),
+		),
+	)
+
+	provideConfigHandler = fx.Provide(
+		fx.Annotate(
+			func(source config.ProvenanceSource) debug.Handler {
+				if source == nil {
+					return debug.Handler{}
+				}
+				return debug.NewConfigHandler(source)
+			},
+			fx.ParamTags(fxtags.Optional),
+			fx.ResultTags(fxtags.Group(debug.GroupHandlers)),
+		),
+	)
+
+	provideSubscriber = fx.Provide(
+		fx.Annotate(
+			func(server *DebugServer) config.Subscriber {
+				return config.Watch(func(_, new debug.Config) {
+					server.Reconfigure(new)
+				})
+			},
+			fx.ResultTags(fxtags.Group(config.GroupConfigSubscribers)),
+		),
+	)
+
+	invokeServer = fx.Invoke(func(*DebugServer) {})
 )
 
+// DebugServer runs the debug HTTP server while the application runs and
+// restarts it when its config changes.
+type DebugServer struct {
+	mu       sync.Mutex
+	config   debug.Config
+	log      fx.Printer
+	handlers []debug.Handler
+	server   *http.Server
+	running  bool
+}
+
 func NewDebugServer(
 	config debug.Config,
 	log fx.Printer,
 	lifecycle fx.Lifecycle,
-) error {
-	if !config.Enabled {
-		log.Printf("debug server not enabled")
-		return nil
+	handlers []debug.Handler,
+) (*DebugServer, error) {
+	if config.Enabled {
+		if _, err := debug.NewHTTPServer(config); err != nil {
+			return nil, err
+		}
 	}
 
-	server, err := debug.NewHTTPServer(config)
-	if err != nil {
-		return err
+	s := &DebugServer{
+		config:   config,
+		log:      log,
+		handlers: handlers,
 	}
 
 	lifecycle.Append(fx.Hook{
 		OnStart: func(context.Context) error {
-			go func() {
-				log.Printf("starting debug server",
-					"address", fmt.Sprintf("http://%s/debug/pprof/", server.Addr),
-				)
+			s.mu.Lock()
+			defer s.mu.Unlock()
 
-				if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-					log.Printf("unable to start debug server", "err", err)
-				}
-			}()
-			return nil
+			s.running = true
+			return s.startLocked()
 		},
 		OnStop: func(ctx context.Context) error {
-			log.Printf("stopping debug server")
-			return server.Shutdown(ctx)
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.running = false
+			return s.stopLocked(ctx)
 		},
 	})
 
+	return s, nil
+}
+
+// Reconfigure restarts the server with config, if the application runs.
+func (s *DebugServer) Reconfigure(config debug.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if config == s.config {
+		return
+	}
+
+	if !s.running {
+		s.config = config
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconfigureTimeout)
+	defer cancel()
+
+	if err := s.stopLocked(ctx); err != nil {
+		s.log.Printf("unable to stop debug server", "err", err)
+	}
+
+	s.config = config
+	if err := s.startLocked(); err != nil {
+		s.log.Printf("unable to start debug server", "err", err)
+	}
+}
+
+func (s *DebugServer) startLocked() error {
+	if !s.config.Enabled {
+		s.log.Printf("debug server not enabled")
+		return nil
+	}
+
+	server, err := debug.NewHTTPServer(s.config, s.handlers...)
+	if err != nil {
+		return err
+	}
+	s.server = server
+
+	go func() {
+		s.log.Printf("starting debug server",
+			"address", fmt.Sprintf("http://%s/debug/pprof/", server.Addr),
+		)
+
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.log.Printf("unable to start debug server", "err", err)
+		}
+	}()
+
 	return nil
 }
+
+func (s *DebugServer) stopLocked(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+
+	s.log.Printf("stopping debug server")
+	err := s.server.Shutdown(ctx)
+	s.server = nil
+	return err
+}
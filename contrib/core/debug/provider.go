@@ -1,5 +1,10 @@
 package debug
 
 const (
+	// ConfigKey is the key of the debug section in the root config.
+	ConfigKey = "debug"
+
 	NamedConfig = "debug.Config"
+
+	GroupHandlers = "debug.Handlers"
 )
@@ -0,0 +1,52 @@
+package features
+
+// Config is the config of the feature flags, keyed by flag name:
+//
+//	features:
+//	  flags:
+//	    new-checkout:
+//	      value: "true"
+//	      rollout: 25
+//	      profiles:
+//	        local:
+//	          value: "true"
+type Config struct {
+	Flags map[string]FlagConfig
+}
+
+// FlagConfig overrides the default value of a flag. The override of the
+// config profile, if any, replaces the flag one.
+type FlagConfig struct {
+	Rule
+
+	Profiles map[string]Rule
+}
+
+// Rule sets the value of a flag for the contexts it targets, the others
+// get the flag default.
+type Rule struct {
+	// Value is the flag value, parsed as the flag type, like true or 42.
+	// The flag default applies when empty.
+	Value string
+
+	// Rollout is the percentage of the contexts getting Value, from 0 to
+	// 100, picked by a hash of their Bucket attribute. All the contexts get
+	// Value when not set, none when 0.
+	Rollout *float64 `validate:"min=0,max=100"`
+
+	// Bucket is the attribute hashed by rollouts, DefaultBucket if empty.
+	// Contexts without it get the flag default.
+	Bucket string
+
+	// Attributes restricts Value to the contexts with, for every attribute,
+	// one of the listed values.
+	Attributes map[string][]string
+}
+
+// rule returns the rule of profile, or else the flag one.
+func (c FlagConfig) rule(profile string) Rule {
+	if r, ok := c.Profiles[profile]; ok && profile != "" {
+		return r
+	}
+	return c.Rule
+}
@@ -0,0 +1,29 @@
+package features
+
+import (
+	"context"
+	"maps"
+)
+
+// Attributes describe the subject of a context, like its user id or
+// country, for rollouts and attribute rules.
+type Attributes map[string]string
+
+type attributesKey struct{}
+
+// WithAttributes returns a copy of ctx with attrs, merged with the
+// attributes of ctx.
+func WithAttributes(ctx context.Context, attrs Attributes) context.Context {
+	merged := maps.Clone(AttributesFrom(ctx))
+	if merged == nil {
+		merged = make(Attributes, len(attrs))
+	}
+	maps.Copy(merged, attrs)
+	return context.WithValue(ctx, attributesKey{}, merged)
+}
+
+// AttributesFrom returns the attributes of ctx.
+func AttributesFrom(ctx context.Context) Attributes {
+	attrs, _ := ctx.Value(attributesKey{}).(Attributes)
+	return attrs
+}
@@ -0,0 +1,309 @@
+// Package features declares typed feature flags, evaluated against the
+// attributes of a context with the rules of the features config:
+//
+//	var newCheckout = features.Bool("new-checkout", false, "new checkout flow")
+//
+//	if newCheckout.Value(ctx) {
+//		...
+//	}
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBucket is the attribute hashed by rollouts without Bucket.
+const DefaultBucket = "id"
+
+// Type is the type of a flag value.
+type Type interface {
+	bool | int | int64 | float64 | string | time.Duration
+}
+
+// Set is a set of flags and the config evaluating them.
+type Set struct {
+	mu    sync.Mutex // serializes declarations and updates
+	flags map[string]declaration
+	state atomic.Pointer[state]
+}
+
+// declaration is a declared flag.
+type declaration struct {
+	typ   string
+	def   string
+	usage string
+	parse func(string) (any, error)
+}
+
+// state is the config of a set, with its rules parsed.
+type state struct {
+	config  Config
+	profile string
+	rules   map[string]rule
+}
+
+// rule is a config rule with its value parsed as the flag type.
+type rule struct {
+	Rule
+	value any
+}
+
+// NewSet returns an empty set, evaluating every flag to its default.
+func NewSet() *Set {
+	s := &Set{flags: make(map[string]declaration)}
+	s.state.Store(&state{})
+	return s
+}
+
+// Default is the set of the flags declared by the package functions.
+var Default = NewSet()
+
+// Fork returns a set with the flags declared in s so far, evaluating them
+// with a config of its own, every flag to its default until updated.
+func (s *Set) Fork() *Set {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fork := NewSet()
+	for name, d := range s.flags {
+		fork.flags[name] = d
+	}
+	return fork
+}
+
+// Flag is a flag of type T.
+type Flag[T Type] struct {
+	set  *Set
+	name string
+	def  T
+}
+
+// Define declares the flag name of type T in s. It panics if the flag is
+// already declared, like the flag package.
+func Define[T Type](s *Set, name string, def T, usage string) *Flag[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.flags[name]; ok {
+		panic(fmt.Sprintf("features: flag %s redefined", name))
+	}
+	s.flags[name] = declaration{
+		typ:   fmt.Sprintf("%T", def),
+		def:   fmt.Sprint(def),
+		usage: usage,
+		parse: func(v string) (any, error) { return parse[T](v) },
+	}
+
+	// rules of the flag were skipped until now
+	current := s.state.Load()
+	s.state.Store(s.compile(current.config, current.profile))
+
+	return &Flag[T]{set: s, name: name, def: def}
+}
+
+// Bool declares a bool flag in Default.
+func Bool(name string, def bool, usage string) *Flag[bool] {
+	return Define(Default, name, def, usage)
+}
+
+// String declares a string flag in Default.
+func String(name string, def string, usage string) *Flag[string] {
+	return Define(Default, name, def, usage)
+}
+
+// Int declares an int flag in Default.
+func Int(name string, def int, usage string) *Flag[int] {
+	return Define(Default, name, def, usage)
+}
+
+// Float64 declares a float64 flag in Default.
+func Float64(name string, def float64, usage string) *Flag[float64] {
+	return Define(Default, name, def, usage)
+}
+
+// Duration declares a time.Duration flag in Default.
+func Duration(name string, def time.Duration, usage string) *Flag[time.Duration] {
+	return Define(Default, name, def, usage)
+}
+
+// Name returns the name of the flag.
+func (f *Flag[T]) Name() string {
+	return f.name
+}
+
+// Value returns the value of the flag for the attributes of ctx.
+func (f *Flag[T]) Value(ctx context.Context) T {
+	return f.ValueIn(ctx, f.set)
+}
+
+// ValueIn returns the value of the flag for the attributes of ctx with the
+// config of s, a fork of the set declaring the flag, or the default if s
+// does not declare it with the same type.
+func (f *Flag[T]) ValueIn(ctx context.Context, s *Set) T {
+	if v, ok := s.state.Load().eval(f.name, AttributesFrom(ctx)); ok {
+		if v, ok := v.(T); ok {
+			return v
+		}
+	}
+	return f.def
+}
+
+// Update replaces the config of the flags of s, for the config profile.
+func (s *Set) Update(cfg Config, profile string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Store(s.compile(cfg, profile))
+}
+
+// Check returns an error for the rules of cfg with values not parsing as
+// the type of their flag.
+func (s *Set) Check(cfg Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, name := range sortedKeys(cfg.Flags) {
+		d, ok := s.flags[name]
+		if !ok {
+			continue
+		}
+
+		fc := cfg.Flags[name]
+		rules := map[string]Rule{"": fc.Rule}
+		for profile, r := range fc.Profiles {
+			rules[profile] = r
+		}
+		for _, profile := range sortedKeys(rules) {
+			if v := rules[profile].Value; v != "" {
+				if _, err := d.parse(v); err != nil {
+					errs = append(errs, fmt.Errorf("features: flag %s: %w", name, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Unknown returns the flags set by cfg but not declared in s, sorted.
+func (s *Set) Unknown(cfg Config) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var unknown []string
+	for _, name := range sortedKeys(cfg.Flags) {
+		if _, ok := s.flags[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// compile returns the state of cfg, parsing the rule values of the
+// declared flags. Rules not parsing are skipped, see Check.
+func (s *Set) compile(cfg Config, profile string) *state {
+	st := &state{
+		config:  cfg,
+		profile: profile,
+		rules:   make(map[string]rule),
+	}
+
+	for name, fc := range cfg.Flags {
+		d, ok := s.flags[name]
+		if !ok {
+			continue
+		}
+
+		r := fc.rule(profile)
+		if r.Value == "" {
+			continue
+		}
+		v, err := d.parse(r.Value)
+		if err != nil {
+			continue
+		}
+		st.rules[name] = rule{Rule: r, value: v}
+	}
+	return st
+}
+
+// eval returns the value of the rule of the flag name for attrs, or false
+// if the flag default applies.
+func (st *state) eval(name string, attrs Attributes) (any, bool) {
+	r, ok := st.rules[name]
+	if !ok {
+		return nil, false
+	}
+
+	for attr, values := range r.Attributes {
+		v, ok := attrs[attr]
+		if !ok || !slices.Contains(values, v) {
+			return nil, false
+		}
+	}
+
+	if r.Rollout != nil && *r.Rollout < 100 {
+		bucket := r.Bucket
+		if bucket == "" {
+			bucket = DefaultBucket
+		}
+		key, ok := attrs[bucket]
+		if !ok || !inRollout(name, key, *r.Rollout) {
+			return nil, false
+		}
+	}
+
+	return r.value, true
+}
+
+// inRollout reports whether the rollout of percentage of the flag name
+// includes key. A key stays in the rollouts of larger percentages.
+func inRollout(name, key string, percentage float64) bool {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) < percentage*100
+}
+
+func parse[T Type](s string) (any, error) {
+	var (
+		v   any
+		err error
+	)
+	switch any(*new(T)).(type) {
+	case bool:
+		v, err = strconv.ParseBool(s)
+	case int:
+		v, err = strconv.Atoi(s)
+	case int64:
+		v, err = strconv.ParseInt(s, 0, 64)
+	case float64:
+		v, err = strconv.ParseFloat(s, 64)
+	case string:
+		v = s
+	case time.Duration:
+		v, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(T), nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
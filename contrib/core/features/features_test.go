@@ -0,0 +1,188 @@
+package features_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/contrib/core/features"
+)
+
+func TestFlagValues(t *testing.T) {
+	set := features.NewSet()
+	checkout := features.Define(set, "new-checkout", false, "new checkout flow")
+	limit := features.Define(set, "limit", 10, "request limit")
+	timeout := features.Define(set, "timeout", time.Second, "request timeout")
+
+	ctx := context.Background()
+	if checkout.Value(ctx) || limit.Value(ctx) != 10 || timeout.Value(ctx) != time.Second {
+		t.Fatal("flags without config do not return their default")
+	}
+
+	set.Update(features.Config{Flags: map[string]features.FlagConfig{
+		"new-checkout": {Rule: features.Rule{Value: "true"}},
+		"limit":        {Rule: features.Rule{Value: "20"}},
+		"timeout": {
+			Rule:     features.Rule{Value: "2s"},
+			Profiles: map[string]features.Rule{"local": {Value: "1m"}},
+		},
+	}}, "local")
+
+	if !checkout.Value(ctx) {
+		t.Error("new-checkout = false, want true")
+	}
+	if got := limit.Value(ctx); got != 20 {
+		t.Errorf("limit = %d, want 20", got)
+	}
+	if got := timeout.Value(ctx); got != time.Minute {
+		t.Errorf("timeout = %s, want the local profile 1m", got)
+	}
+}
+
+func TestFlagAttributes(t *testing.T) {
+	set := features.NewSet()
+	beta := features.Define(set, "beta", false, "")
+
+	set.Update(features.Config{Flags: map[string]features.FlagConfig{
+		"beta": {Rule: features.Rule{
+			Value:      "true",
+			Attributes: map[string][]string{"country": {"fr", "de"}},
+		}},
+	}}, "")
+
+	ctx := features.WithAttributes(context.Background(), features.Attributes{"country": "fr"})
+	if !beta.Value(ctx) {
+		t.Error("beta = false for country fr, want true")
+	}
+
+	ctx = features.WithAttributes(ctx, features.Attributes{"country": "us"})
+	if beta.Value(ctx) {
+		t.Error("beta = true for country us, want false")
+	}
+	if beta.Value(context.Background()) {
+		t.Error("beta = true without attributes, want false")
+	}
+}
+
+func TestFlagRollout(t *testing.T) {
+	set := features.NewSet()
+	rollout := features.Define(set, "rollout", false, "")
+
+	update := func(percentage float64) {
+		set.Update(features.Config{Flags: map[string]features.FlagConfig{
+			"rollout": {Rule: features.Rule{Value: "true", Rollout: &percentage}},
+		}}, "")
+	}
+	enabled := func() map[string]bool {
+		on := map[string]bool{}
+		for i := 0; i < 1000; i++ {
+			id := fmt.Sprint(i)
+			if rollout.Value(features.WithAttributes(context.Background(), features.Attributes{"id": id})) {
+				on[id] = true
+			}
+		}
+		return on
+	}
+
+	update(25)
+	quarter := enabled()
+	if n := len(quarter); n < 200 || n > 300 {
+		t.Fatalf("25%% rollout enabled %d of 1000 ids", n)
+	}
+
+	update(50)
+	half := enabled()
+	for id := range quarter {
+		if !half[id] {
+			t.Fatalf("id %s left the rollout when it grew", id)
+		}
+	}
+
+	if rollout.Value(context.Background()) {
+		t.Error("rollout = true without id, want false")
+	}
+
+	update(0)
+	if n := len(enabled()); n != 0 {
+		t.Errorf("0%% rollout enabled %d of 1000 ids", n)
+	}
+}
+
+func TestRolloutValidation(t *testing.T) {
+	over := 120.0
+	cfg := &features.Config{Flags: map[string]features.FlagConfig{
+		"rollout": {Rule: features.Rule{Value: "true", Rollout: &over}},
+	}}
+	if err := config.Validate(cfg); err == nil || !strings.Contains(err.Error(), "Rollout") {
+		t.Errorf("Validate() error = %v, want Rollout error", err)
+	}
+}
+
+func TestSetFork(t *testing.T) {
+	set := features.NewSet()
+	beta := features.Define(set, "beta", false, "")
+	set.Update(features.Config{Flags: map[string]features.FlagConfig{
+		"beta": {Rule: features.Rule{Value: "true"}},
+	}}, "")
+
+	fork := set.Fork()
+	ctx := context.Background()
+	if beta.ValueIn(ctx, fork) {
+		t.Error("beta = true in the fork before its update, want the default false")
+	}
+
+	fork.Update(features.Config{Flags: map[string]features.FlagConfig{
+		"beta": {Rule: features.Rule{Value: "false"}},
+	}}, "")
+	set.Update(features.Config{Flags: map[string]features.FlagConfig{
+		"beta": {Rule: features.Rule{Value: "true"}},
+	}}, "")
+	if beta.ValueIn(ctx, fork) || !beta.Value(ctx) {
+		t.Error("the fork and its set do not evaluate their own config")
+	}
+}
+
+func TestSetCheck(t *testing.T) {
+	set := features.NewSet()
+	features.Define(set, "limit", 10, "")
+
+	cfg := features.Config{Flags: map[string]features.FlagConfig{
+		"limit": {Profiles: map[string]features.Rule{"prod": {Value: "many"}}},
+		"gone":  {Rule: features.Rule{Value: "true"}},
+	}}
+
+	if err := set.Check(cfg); err == nil || !strings.Contains(err.Error(), "flag limit") {
+		t.Errorf("Check() error = %v, want limit parse error", err)
+	}
+	if got := set.Unknown(cfg); len(got) != 1 || got[0] != "gone" {
+		t.Errorf("Unknown() = %v, want [gone]", got)
+	}
+}
+
+func TestHandlerListsFlags(t *testing.T) {
+	set := features.NewSet()
+	features.Define(set, "beta", false, "beta features")
+	set.Update(features.Config{Flags: map[string]features.FlagConfig{
+		"beta": {Rule: features.Rule{Value: "true", Attributes: map[string][]string{"country": {"fr"}}}},
+	}}, "")
+
+	rec := httptest.NewRecorder()
+	features.NewHandler(set).ServeHTTP(rec, httptest.NewRequest("GET", features.HandlerPattern+"?country=fr", nil))
+
+	got := rec.Body.String()
+	for _, want := range []string{"beta", "bool", "true", "false", "country=fr"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	features.NewHandler(set).ServeHTTP(rec, httptest.NewRequest("GET", features.HandlerPattern+"?format=json", nil))
+	if !strings.Contains(rec.Body.String(), `"value":"false"`) {
+		t.Errorf("JSON output = %s, want beta false without country", rec.Body.String())
+	}
+}
@@ -0,0 +1,158 @@
+package featuresfx
+
+import (
+	"log/slog"
+
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/config/configfx"
+	"github.com/go-toho/toho/contrib/core/debug"
+	"github.com/go-toho/toho/contrib/core/features"
+	"github.com/go-toho/toho/pkg/fxtags"
+)
+
+var Module = fx.Module("features",
+	configfx.SupplyKey(config.KeyFor[features.Config](features.ConfigKey)),
+	provideConfigPointer,
+	provideSet,
+	provideSubscriber,
+	provideHandler,
+	invokeSet,
+)
+
+var (
+	provideConfigPointer = fx.Provide(
+		fx.Annotate(
+			func(cfg any, root any) *features.Config {
+				if cfg != nil {
+					switch v := cfg.(type) {
+					case *features.Config:
+						return v
+					case features.Config:
+						return &v
+					default:
+						break
+					}
+				}
+				if v, ok := config.BindSection[features.Config](root, features.ConfigKey); ok {
+					return v
+				}
+				return &features.Config{}
+			},
+			fx.ParamTags(
+				fxtags.NamedOptional(features.NamedConfig),
+				fxtags.NamedOptional(config.NamedConfigPointerOut),
+			),
+			fx.ResultTags(fxtags.Named(features.NamedConfig)),
+		),
+	)
+
+	provideSet = fx.Provide(
+		fx.Annotate(
+			newSet,
+			fx.ParamTags(
+				fxtags.Named(features.NamedConfig),
+				fxtags.NamedOptional(config.NamedProfile),
+				fxtags.Optional,
+			),
+		),
+	)
+
+	provideSubscriber = fx.Provide(
+		fx.Annotate(
+			newSubscriber,
+			fx.ParamTags(
+				fxtags.Empty,
+				fxtags.NamedOptional(config.NamedProfile),
+				fxtags.Optional,
+			),
+			fx.ResultTags(fxtags.Group(config.GroupConfigSubscribers)),
+		),
+	)
+
+	provideHandler = fx.Provide(
+		fx.Annotate(
+			func(set *features.Set) debug.Handler {
+				return debug.Handler{
+					Pattern: features.HandlerPattern,
+					Handler: features.NewHandler(set),
+				}
+			},
+			fx.ResultTags(fxtags.Group(debug.GroupHandlers)),
+		),
+	)
+
+	invokeSet = fx.Invoke(func(*features.Set) {})
+)
+
+// SetAsDefault evaluates the flags declared by the package functions, in
+// features.Default, with the config of the app.
+var SetAsDefault = fx.Options(
+	fx.Invoke(
+		fx.Annotate(
+			func(cfg *features.Config, profile string, log *slog.Logger) error {
+				return update(features.Default, *cfg, profile, log)
+			},
+			fx.ParamTags(
+				fxtags.Named(features.NamedConfig),
+				fxtags.NamedOptional(config.NamedProfile),
+				fxtags.Optional,
+			),
+		),
+	),
+	fx.Provide(
+		fx.Annotate(
+			func(profile string, log *slog.Logger) config.Subscriber {
+				return newSubscriber(features.Default, profile, log)
+			},
+			fx.ParamTags(
+				fxtags.NamedOptional(config.NamedProfile),
+				fxtags.Optional,
+			),
+			fx.ResultTags(fxtags.Group(config.GroupConfigSubscribers)),
+		),
+	),
+)
+
+// newSet returns a fork of features.Default, with the flags declared by
+// the package functions, evaluated with cfg.
+func newSet(cfg *features.Config, profile string, log *slog.Logger) (*features.Set, error) {
+	set := features.Default.Fork()
+	if err := update(set, *cfg, profile, log); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// newSubscriber returns a subscriber updating set on reload, skipping the
+// rules of an invalid config.
+func newSubscriber(set *features.Set, profile string, log *slog.Logger) config.Subscriber {
+	return config.Watch(func(_, new features.Config) {
+		if err := set.Check(new); err != nil {
+			loggerOrDefault(log).Error("invalid feature flags, skipping their rules", slog.Any("error", err))
+		}
+		set.Update(new, profile)
+	})
+}
+
+// update checks cfg and updates set with it, warning about the flags not
+// declared in set.
+func update(set *features.Set, cfg features.Config, profile string, log *slog.Logger) error {
+	if err := set.Check(cfg); err != nil {
+		return err
+	}
+	for _, name := range set.Unknown(cfg) {
+		loggerOrDefault(log).Warn("feature flag not declared", slog.String("flag", name))
+	}
+
+	set.Update(cfg, profile)
+	return nil
+}
+
+func loggerOrDefault(log *slog.Logger) *slog.Logger {
+	if log == nil {
+		return slog.Default()
+	}
+	return log
+}
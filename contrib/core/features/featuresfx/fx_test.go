@@ -0,0 +1,91 @@
+package featuresfx_test
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/config/configfx"
+	"github.com/go-toho/toho/contrib/core/features"
+	"github.com/go-toho/toho/contrib/core/features/featuresfx"
+)
+
+type appConfig struct {
+	Features features.Config
+}
+
+var reloadedFlag = features.Bool("featuresfx-test-reloaded", false, "")
+
+func TestModuleFollowsReloads(t *testing.T) {
+	cfg := &appConfig{}
+	src := &appConfig{Features: features.Config{Flags: map[string]features.FlagConfig{
+		reloadedFlag.Name(): {Rule: features.Rule{Value: "true"}},
+	}}}
+
+	var reloader *config.Reloader
+	app := fx.New(
+		fx.NopLogger,
+		configfx.SupplyConfigPointer(cfg),
+		configfx.SupplyConfig(cfg),
+		configfx.SupplyProfile(config.ProfileProd),
+		configfx.SupplyLoadFunc(func(dst any) error {
+			*dst.(*appConfig) = *src
+			return nil
+		}),
+		configfx.Module,
+		featuresfx.Module,
+		featuresfx.SetAsDefault,
+		fx.Populate(&reloader),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("expected app to build, got error: %v", err)
+	}
+
+	ctx := context.Background()
+	if reloadedFlag.Value(ctx) {
+		t.Fatal("flag = true before reload, want the default false")
+	}
+
+	if err := reloader.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !reloadedFlag.Value(ctx) {
+		t.Fatal("flag = false after reload, want true")
+	}
+}
+
+var appFlag = features.Bool("featuresfx-test-app", false, "")
+
+func TestModuleKeepsAppsApart(t *testing.T) {
+	newApp := func(value string) *features.Set {
+		cfg := &appConfig{Features: features.Config{Flags: map[string]features.FlagConfig{
+			appFlag.Name(): {Rule: features.Rule{Value: value}},
+		}}}
+
+		var set *features.Set
+		app := fx.New(
+			fx.NopLogger,
+			configfx.SupplyConfigPointer(cfg),
+			configfx.SupplyConfig(cfg),
+			configfx.Module,
+			featuresfx.Module,
+			fx.Populate(&set),
+		)
+		if err := app.Err(); err != nil {
+			t.Fatalf("expected app to build, got error: %v", err)
+		}
+		return set
+	}
+
+	on, off := newApp("true"), newApp("false")
+
+	ctx := context.Background()
+	if !appFlag.ValueIn(ctx, on) || appFlag.ValueIn(ctx, off) {
+		t.Error("the apps do not evaluate the flag with their own config")
+	}
+	if appFlag.Value(ctx) {
+		t.Error("flag = true in features.Default, want it untouched without SetAsDefault")
+	}
+}
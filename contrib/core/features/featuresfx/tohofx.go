@@ -0,0 +1,13 @@
+package featuresfx
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/tohofx"
+)
+
+func init() {
+	tohofx.Add("features", func() fx.Option {
+		return fx.Options(Module, SetAsDefault)
+	})
+}
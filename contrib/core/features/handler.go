@@ -0,0 +1,107 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+)
+
+// HandlerPattern is the pattern of the flags endpoint.
+const HandlerPattern = "/debug/features"
+
+// State is the state of a flag for some attributes.
+type State struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default"`
+	Value   string `json:"value"`
+	Usage   string `json:"usage,omitempty"`
+
+	// Rule is the rule of the config profile, if the config sets the flag.
+	Rule *Rule `json:"rule,omitempty"`
+}
+
+// States returns the state of every flag of s for the attributes of ctx,
+// sorted by name.
+func (s *Set) States(ctx context.Context) []State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.state.Load()
+	attrs := AttributesFrom(ctx)
+
+	states := make([]State, 0, len(s.flags))
+	for _, name := range sortedKeys(s.flags) {
+		d := s.flags[name]
+		state := State{
+			Name:    name,
+			Type:    d.typ,
+			Default: d.def,
+			Value:   d.def,
+			Usage:   d.usage,
+		}
+		if fc, ok := st.config.Flags[name]; ok {
+			r := fc.rule(st.profile)
+			state.Rule = &r
+		}
+		if v, ok := st.eval(name, attrs); ok {
+			state.Value = fmt.Sprint(v)
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// NewHandler returns a handler listing the flags of s, as a text table or
+// as JSON with ?format=json. The other query parameters are the attributes
+// the flags are evaluated for.
+func NewHandler(s *Set) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		attrs := Attributes{}
+		for name, values := range query {
+			if name != "format" && len(values) > 0 {
+				attrs[name] = values[0]
+			}
+		}
+		states := s.States(WithAttributes(r.Context(), attrs))
+
+		if query.Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(states)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = writeText(w, states)
+	})
+}
+
+func writeText(w io.Writer, states []State) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tVALUE\tDEFAULT\tRULE")
+	for _, s := range states {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Type, s.Value, s.Default, ruleText(s.Rule))
+	}
+	return tw.Flush()
+}
+
+func ruleText(r *Rule) string {
+	if r == nil || r.Value == "" {
+		return "-"
+	}
+
+	parts := []string{"value=" + r.Value}
+	if r.Rollout != nil && *r.Rollout < 100 {
+		parts = append(parts, fmt.Sprintf("rollout=%g%%", *r.Rollout))
+	}
+	for _, attr := range sortedKeys(r.Attributes) {
+		parts = append(parts, attr+"="+strings.Join(r.Attributes[attr], "|"))
+	}
+	return strings.Join(parts, " ")
+}
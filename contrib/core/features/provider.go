@@ -0,0 +1,8 @@
+package features
+
+const (
+	// ConfigKey is the key of the features section in the root config.
+	ConfigKey = "features"
+
+	NamedConfig = "features.Config"
+)
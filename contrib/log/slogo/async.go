@@ -0,0 +1,188 @@
+package slogo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// DefaultAsyncBuffer is the buffer size of an async handler when not
+// configured.
+const DefaultAsyncBuffer = 1024
+
+// AsyncHandler handles the records of the handler it wraps in the
+// background, buffering them in a bounded ring. The errors of the wrapped
+// handler are ignored. Once stopped, it handles the records synchronously.
+type AsyncHandler struct {
+	handler slog.Handler
+	queue   *asyncQueue
+}
+
+var _ slog.Handler = &AsyncHandler{}
+
+// asyncQueue is the ring of the buffered records, shared by the handlers
+// derived with WithAttrs and WithGroup.
+type asyncQueue struct {
+	handler  slog.Handler
+	overflow string
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	entries  []asyncEntry
+	head     int
+	size     int
+	stopped  bool
+
+	dropped atomic.Uint64
+	done    chan struct{}
+}
+
+type asyncEntry struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// NewAsyncHandler returns a handler handling the records of handler in the
+// background as set by config, until stopped.
+func NewAsyncHandler(handler slog.Handler, config logger.Async) (*AsyncHandler, error) {
+	size := config.Buffer
+	if size <= 0 {
+		size = DefaultAsyncBuffer
+	}
+
+	q := &asyncQueue{
+		handler:  handler,
+		overflow: config.Overflow,
+		entries:  make([]asyncEntry, size),
+		done:     make(chan struct{}),
+	}
+	switch q.overflow {
+	case "":
+		q.overflow = logger.BlockOverflow
+	case logger.BlockOverflow, logger.DropOldestOverflow, logger.DropNewestOverflow:
+	default:
+		return nil, fmt.Errorf("unsupported async overflow: %q", config.Overflow)
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+
+	go q.run()
+	return &AsyncHandler{handler: handler, queue: q}, nil
+}
+
+// Handler returns the wrapped handler.
+func (h *AsyncHandler) Handler() slog.Handler {
+	return h.handler
+}
+
+// Dropped returns the number of records dropped by the overflow policy.
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.queue.dropped.Load()
+}
+
+// Stop handles the buffered records, waiting for them until ctx is done,
+// and reports the dropped ones, if any.
+func (h *AsyncHandler) Stop(ctx context.Context) error {
+	q := h.queue
+
+	q.mu.Lock()
+	q.stopped = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+		return fmt.Errorf("flush async log records: %w", ctx.Err())
+	}
+
+	if dropped := q.dropped.Swap(0); dropped > 0 && q.handler.Enabled(ctx, slog.LevelWarn) {
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, "log records dropped by the async handler", 0)
+		r.AddAttrs(slog.Uint64("dropped", dropped))
+		_ = q.handler.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle buffers r, or handles it if the handler is stopped.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	entry := asyncEntry{ctx: context.WithoutCancel(ctx), handler: h.handler, record: r.Clone()}
+	if h.queue.push(entry) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{handler: h.handler.WithAttrs(attrs), queue: h.queue}
+}
+
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{handler: h.handler.WithGroup(name), queue: h.queue}
+}
+
+// push buffers e, applying the overflow policy if the ring is full. It
+// returns false if the queue is stopped.
+func (q *asyncQueue) push(e asyncEntry) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.stopped && q.size == len(q.entries) {
+		switch q.overflow {
+		case logger.DropNewestOverflow:
+			q.dropped.Add(1)
+			return true
+		case logger.DropOldestOverflow:
+			q.entries[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.entries)
+			q.size--
+			q.dropped.Add(1)
+		default:
+			q.notFull.Wait()
+		}
+	}
+	if q.stopped {
+		return false
+	}
+
+	q.entries[(q.head+q.size)%len(q.entries)] = e
+	q.size++
+	q.notEmpty.Signal()
+	return true
+}
+
+// run handles the buffered records until the queue is stopped and empty.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+		for q.size == 0 && !q.stopped {
+			q.notEmpty.Wait()
+		}
+		if q.size == 0 {
+			q.mu.Unlock()
+			return
+		}
+		e := q.entries[q.head]
+		q.entries[q.head] = asyncEntry{}
+		q.head = (q.head + 1) % len(q.entries)
+		q.size--
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		_ = e.handler.Handle(e.ctx, e.record)
+	}
+}
@@ -0,0 +1,130 @@
+package slogo
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// blockingHandler is a Handler just for testing that records the messages
+// once released.
+type blockingHandler struct {
+	testHandler
+	release chan struct{}
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, r slog.Record) error {
+	<-h.release
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, r.Message)
+	return nil
+}
+
+func TestAsyncHandlerFlushesOnStop(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewAsyncHandler(slog.NewTextHandler(&buf, nil), logger.Async{Enabled: true, Buffer: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(handler).With("component", "orders")
+	for i := 0; i < 10; i++ {
+		log.Info("buffered", "i", i)
+	}
+	if err := handler.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("after stop")
+
+	out := buf.String()
+	if n := strings.Count(out, "msg=buffered component=orders"); n != 10 {
+		t.Errorf("expected every buffered record, got %d in %s", n, out)
+	}
+	if !strings.Contains(out, "msg=\"after stop\"") {
+		t.Errorf("expected records after stop to be handled, got %s", out)
+	}
+}
+
+func TestAsyncHandlerOverflow(t *testing.T) {
+	tests := []struct {
+		overflow string
+		want     []string
+	}{
+		{logger.DropNewestOverflow, []string{"0", "1", "2"}},
+		{logger.DropOldestOverflow, []string{"0", "4", "5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.overflow, func(t *testing.T) {
+			inner := &blockingHandler{release: make(chan struct{})}
+			handler, err := NewAsyncHandler(inner, logger.Async{Enabled: true, Buffer: 2, Overflow: tt.overflow})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			log := slog.New(handler)
+			log.Info("0")
+			// the first record is taken by the blocked handler
+			for deadline := time.Now().Add(time.Second); buffered(handler) > 0; time.Sleep(time.Millisecond) {
+				if time.Now().After(deadline) {
+					t.Fatal("expected the first record to be taken")
+				}
+			}
+			for _, msg := range []string{"1", "2", "3", "4", "5"} {
+				log.Info(msg)
+			}
+			if dropped := handler.Dropped(); dropped != 3 {
+				t.Errorf("expected 3 dropped records, got %d", dropped)
+			}
+
+			close(inner.release)
+			if err := handler.Stop(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := inner.messages[:3]; strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, inner.messages)
+			}
+			if last := inner.messages[len(inner.messages)-1]; last != "log records dropped by the async handler" {
+				t.Errorf("expected dropped records report, got %q", last)
+			}
+		})
+	}
+}
+
+func buffered(h *AsyncHandler) int {
+	h.queue.mu.Lock()
+	defer h.queue.mu.Unlock()
+	return h.queue.size
+}
+
+func TestAsyncHandlerStopTimeout(t *testing.T) {
+	inner := &blockingHandler{release: make(chan struct{})}
+	defer close(inner.release)
+
+	handler, err := NewAsyncHandler(inner, logger.Async{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.New(handler).Info("stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := handler.Stop(ctx); err == nil {
+		t.Error("expected stop to time out")
+	}
+}
+
+func TestAsyncHandlerInvalidOverflow(t *testing.T) {
+	if _, err := NewAsyncHandler(&testHandler{}, logger.Async{Overflow: "spill"}); err == nil {
+		t.Error("expected an error for an unsupported overflow")
+	}
+}
@@ -0,0 +1,126 @@
+package slogo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-toho/toho/app"
+)
+
+// Keys of the attributes added by ContextHandler.
+const (
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+	RequestIDKey = "request_id"
+	AppKey       = "app"
+)
+
+type (
+	attrsKey     struct{}
+	requestIDKey struct{}
+)
+
+// ContextWith returns a copy of ctx carrying the attributes of args, in the
+// format of slog.Logger.With, added to the records logged with it by a
+// ContextHandler.
+func ContextWith(ctx context.Context, args ...any) context.Context {
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	if r.NumAttrs() == 0 {
+		return ctx
+	}
+
+	parent, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	attrs := make([]slog.Attr, len(parent), len(parent)+r.NumAttrs())
+	copy(attrs, parent)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the request ID of ctx, if any.
+func RequestIDFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ContextAttrs returns the attributes of ctx: the IDs of its span, its
+// request ID, its app info and the attributes added by ContextWith.
+func ContextAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs,
+			slog.String(TraceIDKey, sc.TraceID().String()),
+			slog.String(SpanIDKey, sc.SpanID().String()),
+		)
+	}
+	if id, ok := RequestIDFrom(ctx); ok {
+		attrs = append(attrs, slog.String(RequestIDKey, id))
+	}
+	if info, ok := app.FromContext(ctx); ok {
+		attrs = append(attrs, slog.Group(AppKey,
+			slog.String("id", info.ID()),
+			slog.String("name", info.Name()),
+			slog.String("version", info.Version()),
+		))
+	}
+	if added, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		attrs = append(attrs, added...)
+	}
+
+	return attrs
+}
+
+// ContextHandler adds the attributes of ContextAttrs to the records of the
+// handler it wraps. Like the record attributes, they are qualified by the
+// groups of the logger.
+type ContextHandler struct {
+	handler slog.Handler
+}
+
+var _ slog.Handler = &ContextHandler{}
+
+// NewContextHandler returns a handler adding the context attributes to the
+// records of handler.
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{handler: handler}
+}
+
+// Handler returns the wrapped handler.
+func (h *ContextHandler) Handler() slog.Handler {
+	return h.handler
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.handler.Handle(ctx, r)
+	}
+	if attrs := ContextAttrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{handler: h.handler.WithGroup(name)}
+}
@@ -0,0 +1,61 @@
+package slogo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-toho/toho/app"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = app.NewContext(ctx, app.New(app.ID("1"), app.Name("orders"), app.Version("v1")))
+	ctx = ContextWith(ctx, "user", "alice")
+	ctx = ContextWith(ctx, slog.Int("attempt", 2))
+
+	log.InfoContext(ctx, "request", slog.String("path", "/orders"))
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"path":       "/orders",
+		TraceIDKey:   sc.TraceID().String(),
+		SpanIDKey:    sc.SpanID().String(),
+		RequestIDKey: "req-1",
+		"user":       "alice",
+		"attempt":    float64(2),
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, got[k])
+		}
+	}
+	if a, _ := got[AppKey].(map[string]any); a["name"] != "orders" || a["version"] != "v1" {
+		t.Errorf("expected app info, got %v", got[AppKey])
+	}
+
+	buf.Reset()
+	log.Info("plain")
+	got = nil
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("expected no context attributes without context, got %v", got)
+	}
+}
@@ -0,0 +1,434 @@
+package slogo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LevelPattern is the pattern of the log level endpoint.
+const LevelPattern = "/debug/loglevel"
+
+// LevelControl changes the minimum level of loggers at runtime. Every
+// logger level var has a configured level, which an override replaces for
+// all of them until it is reset or expires.
+//
+// It also holds the levels of the loggers named with WithName, matching
+// the name and its parents on the / separated elements, which take
+// precedence over the logger level with a NameHandler.
+type LevelControl struct {
+	mu       sync.Mutex
+	levels   []*trackedLevel
+	override atomic.Pointer[slog.Level]
+	expires  time.Time
+	timer    *time.Timer
+
+	configuredNames map[string]slog.Level
+	nameOverrides   map[string]*nameOverride
+	names           atomic.Pointer[map[string]slog.Level]
+}
+
+type nameOverride struct {
+	level   slog.Level
+	expires time.Time
+	timer   *time.Timer
+}
+
+type trackedLevel struct {
+	name       string
+	level      *slog.LevelVar
+	configured slog.Level
+}
+
+// LevelState is the state of a LevelControl.
+type LevelState struct {
+	// Override is the level replacing the configured ones, if any, until
+	// Expires if not zero.
+	Override string     `json:"override,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+
+	// Loggers are the current levels of the loggers, by name.
+	Loggers map[string]string `json:"loggers"`
+
+	// Names are the current levels of the named loggers, by name prefix,
+	// and NameExpires the expiry of their overrides.
+	Names       map[string]string    `json:"names,omitempty"`
+	NameExpires map[string]time.Time `json:"nameExpires,omitempty"`
+}
+
+// NewLevelControl returns a control without loggers.
+func NewLevelControl() *LevelControl {
+	return &LevelControl{}
+}
+
+// Levels controls the levels of the handlers returned by NewHandler.
+var Levels = NewLevelControl()
+
+// Track controls level, named name, whose configured level is the current
+// one, until untracked.
+func (c *LevelControl) Track(name string, level *slog.LevelVar) (untrack func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tracked := &trackedLevel{name: name, level: level, configured: level.Level()}
+	c.levels = append(c.levels, tracked)
+	if override := c.override.Load(); override != nil {
+		level.Set(*override)
+	}
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.levels = slices.DeleteFunc(c.levels, func(l *trackedLevel) bool { return l == tracked })
+	}
+}
+
+// Leveler returns the level of a logger configured with level, replaced by
+// the overrides of c. Unlike a tracked level, it is not configured by name
+// nor listed by State, and needs no untracking.
+func (c *LevelControl) Leveler(level slog.Level) slog.Leveler {
+	return controlledLevel{control: c, configured: level}
+}
+
+type controlledLevel struct {
+	control    *LevelControl
+	configured slog.Level
+}
+
+func (l controlledLevel) Level() slog.Level {
+	if override := l.control.override.Load(); override != nil {
+		return *override
+	}
+	return l.configured
+}
+
+// Configure sets the configured level of the loggers named name, applied
+// unless overridden.
+func (c *LevelControl) Configure(name string, level slog.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, l := range c.levels {
+		if l.name != name {
+			continue
+		}
+		l.configured = level
+		if c.override.Load() == nil {
+			l.level.Set(level)
+		}
+	}
+}
+
+// Set overrides the level of every logger, reverting to the configured
+// levels after ttl if positive.
+func (c *LevelControl) Set(level slog.Level, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimerLocked()
+	c.override.Store(&level)
+	for _, l := range c.levels {
+		l.level.Set(level)
+	}
+
+	if ttl > 0 {
+		c.expires = time.Now().Add(ttl)
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			// a later change replaced the timer
+			if c.timer == timer {
+				c.resetLocked()
+			}
+		})
+		c.timer = timer
+	}
+}
+
+// Reset reverts every logger, named ones included, to its configured
+// level.
+func (c *LevelControl) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resetLocked()
+	for name := range c.nameOverrides {
+		c.resetNameLocked(name)
+	}
+	c.updateNamesLocked()
+}
+
+// ConfigureNames sets the configured levels of the named loggers, by name
+// prefix, applied unless overridden.
+func (c *LevelControl) ConfigureNames(levels map[string]slog.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.configuredNames = make(map[string]slog.Level, len(levels))
+	for name, level := range levels {
+		c.configuredNames[cleanName(name)] = level
+	}
+	c.updateNamesLocked()
+}
+
+// SetName overrides the level of the loggers named name or one of its
+// children, reverting to the configured levels after ttl if positive.
+func (c *LevelControl) SetName(name string, level slog.Level, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name = cleanName(name)
+	c.resetNameLocked(name)
+
+	o := &nameOverride{level: level}
+	if ttl > 0 {
+		o.expires = time.Now().Add(ttl)
+		o.timer = time.AfterFunc(ttl, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			// a later change replaced the override
+			if c.nameOverrides[name] == o {
+				c.resetNameLocked(name)
+				c.updateNamesLocked()
+			}
+		})
+	}
+	if c.nameOverrides == nil {
+		c.nameOverrides = make(map[string]*nameOverride)
+	}
+	c.nameOverrides[name] = o
+	c.updateNamesLocked()
+}
+
+// ResetName reverts the loggers named name or one of its children to
+// their configured level.
+func (c *LevelControl) ResetName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resetNameLocked(cleanName(name))
+	c.updateNamesLocked()
+}
+
+// NameLevel returns the level of the loggers named name: the one of the
+// longest prefix of its / separated elements with a level, or false if
+// none has one.
+func (c *LevelControl) NameLevel(name string) (slog.Level, bool) {
+	return controlledNames{control: c}.NameLevel(name)
+}
+
+// NameLeveler returns the levels of the named loggers configured with
+// levels, by name prefix, and by the levels of c, which take precedence
+// for a same prefix. Unlike ConfigureNames, it leaves c unchanged.
+func (c *LevelControl) NameLeveler(levels map[string]slog.Level) NameLeveler {
+	configured := make(map[string]slog.Level, len(levels))
+	for name, level := range levels {
+		configured[cleanName(name)] = level
+	}
+	return controlledNames{control: c, configured: configured}
+}
+
+type controlledNames struct {
+	control    *LevelControl
+	configured map[string]slog.Level
+}
+
+func (l controlledNames) NameLevel(name string) (slog.Level, bool) {
+	var names map[string]slog.Level
+	if p := l.control.names.Load(); p != nil {
+		names = *p
+	}
+	if len(names) == 0 && len(l.configured) == 0 {
+		return 0, false
+	}
+
+	for name != "" {
+		if level, ok := names[name]; ok {
+			return level, true
+		}
+		if level, ok := l.configured[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '/')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return 0, false
+}
+
+func (c *LevelControl) resetNameLocked(name string) {
+	if o, ok := c.nameOverrides[name]; ok {
+		if o.timer != nil {
+			o.timer.Stop()
+		}
+		delete(c.nameOverrides, name)
+	}
+}
+
+func (c *LevelControl) updateNamesLocked() {
+	names := make(map[string]slog.Level, len(c.configuredNames)+len(c.nameOverrides))
+	for name, level := range c.configuredNames {
+		names[name] = level
+	}
+	for name, o := range c.nameOverrides {
+		names[name] = o.level
+	}
+	c.names.Store(&names)
+}
+
+// cleanName returns name without leading and trailing separators.
+func cleanName(name string) string {
+	return strings.Trim(name, "/")
+}
+
+func (c *LevelControl) resetLocked() {
+	c.stopTimerLocked()
+	c.override.Store(nil)
+	for _, l := range c.levels {
+		l.level.Set(l.configured)
+	}
+}
+
+func (c *LevelControl) stopTimerLocked() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.expires = time.Time{}
+}
+
+// State returns the override and the current levels.
+func (c *LevelControl) State() LevelState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := LevelState{
+		Loggers: make(map[string]string, len(c.levels)),
+	}
+	if override := c.override.Load(); override != nil {
+		state.Override = override.String()
+	}
+	if !c.expires.IsZero() {
+		expires := c.expires
+		state.Expires = &expires
+	}
+	for _, l := range c.levels {
+		state.Loggers[l.name] = l.level.Level().String()
+	}
+	if names := c.names.Load(); names != nil && len(*names) > 0 {
+		state.Names = make(map[string]string, len(*names))
+		for name, level := range *names {
+			state.Names[name] = level.String()
+		}
+	}
+	for name, o := range c.nameOverrides {
+		if !o.expires.IsZero() {
+			if state.NameExpires == nil {
+				state.NameExpires = make(map[string]time.Time)
+			}
+			state.NameExpires[name] = o.expires
+		}
+	}
+	return state
+}
+
+// ToggleOnSignal overrides the levels with level for ttl whenever one of
+// the signals is received, or resets them if overridden, until ctx is done.
+func (c *LevelControl) ToggleOnSignal(ctx context.Context, level slog.Level, ttl time.Duration, signals ...os.Signal) {
+	if len(signals) == 0 {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	go func() {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				if c.State().Override != "" {
+					c.Reset()
+				} else {
+					c.Set(level, ttl)
+				}
+			}
+		}
+	}()
+}
+
+// ServeHTTP returns the state of the control as JSON on GET. On PUT, it
+// overrides the levels with the level parameter for the optional ttl
+// parameter, like ?level=debug&ttl=10m, or resets them without level. With
+// a name parameter, like ?name=orders/db&level=debug, it only changes the
+// level of the loggers with that name.
+func (c *LevelControl) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		if err := c.update(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(c.State())
+}
+
+func (c *LevelControl) update(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	name := cleanName(r.Form.Get("name"))
+	text := r.Form.Get("level")
+	if text == "" {
+		if name != "" {
+			c.ResetName(name)
+		} else {
+			c.Reset()
+		}
+		return nil
+	}
+	level, err := ParseLevel(text)
+	if err != nil {
+		return err
+	}
+
+	var ttl time.Duration
+	if text := r.Form.Get("ttl"); text != "" {
+		if ttl, err = time.ParseDuration(text); err != nil {
+			return fmt.Errorf("invalid ttl: %w", err)
+		}
+	}
+
+	if name != "" {
+		c.SetName(name, level, ttl)
+	} else {
+		c.Set(level, ttl)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package slogo
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLevelControl(t *testing.T) {
+	control := NewLevelControl()
+
+	main, fx := new(slog.LevelVar), new(slog.LevelVar)
+	fx.Set(slog.LevelWarn)
+	control.Track(DefaultLevelName, main)
+	control.Track(FxLevelName, fx)
+
+	control.Set(slog.LevelDebug, 0)
+	if main.Level() != slog.LevelDebug || fx.Level() != slog.LevelDebug {
+		t.Fatalf("expected overridden levels, got %v and %v", main.Level(), fx.Level())
+	}
+
+	// configured levels apply once reset
+	control.Configure(DefaultLevelName, slog.LevelError)
+	if main.Level() != slog.LevelDebug {
+		t.Errorf("expected override to stay, got %v", main.Level())
+	}
+
+	control.Reset()
+	if main.Level() != slog.LevelError || fx.Level() != slog.LevelWarn {
+		t.Errorf("expected configured levels, got %v and %v", main.Level(), fx.Level())
+	}
+	if state := control.State(); state.Override != "" || state.Expires != nil {
+		t.Errorf("expected no override, got %+v", state)
+	}
+}
+
+func TestLevelControlTTL(t *testing.T) {
+	control := NewLevelControl()
+
+	level := new(slog.LevelVar)
+	control.Track(DefaultLevelName, level)
+
+	control.Set(slog.LevelDebug, 10*time.Millisecond)
+	if state := control.State(); state.Override != "DEBUG" || state.Expires == nil {
+		t.Errorf("expected override with expiry, got %+v", state)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for level.Level() != slog.LevelInfo {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected level to revert, got %v", level.Level())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLevelControlUntrack(t *testing.T) {
+	control := NewLevelControl()
+
+	level := new(slog.LevelVar)
+	untrack := control.Track(DefaultLevelName, level)
+	untrack()
+
+	control.Set(slog.LevelDebug, 0)
+	if level.Level() != slog.LevelInfo {
+		t.Errorf("expected untracked level to stay, got %v", level.Level())
+	}
+	if state := control.State(); len(state.Loggers) != 0 {
+		t.Errorf("expected no loggers, got %v", state.Loggers)
+	}
+}
+
+func TestLevelControlLeveler(t *testing.T) {
+	control := NewLevelControl()
+
+	level := control.Leveler(slog.LevelWarn)
+	control.Set(slog.LevelDebug, 0)
+	if level.Level() != slog.LevelDebug {
+		t.Errorf("expected overridden level, got %v", level.Level())
+	}
+	control.Reset()
+	if level.Level() != slog.LevelWarn {
+		t.Errorf("expected configured level, got %v", level.Level())
+	}
+	if state := control.State(); len(state.Loggers) != 0 {
+		t.Errorf("expected leveler not to be tracked, got %v", state.Loggers)
+	}
+}
+
+func TestLevelControlHTTP(t *testing.T) {
+	control := NewLevelControl()
+
+	level := new(slog.LevelVar)
+	control.Track(DefaultLevelName, level)
+
+	tests := []struct {
+		method string
+		target string
+		code   int
+		level  slog.Level
+	}{
+		{http.MethodGet, LevelPattern, http.StatusOK, slog.LevelInfo},
+		{http.MethodPut, LevelPattern + "?level=debug&ttl=1m", http.StatusOK, slog.LevelDebug},
+		{http.MethodPut, LevelPattern + "?level=loud", http.StatusBadRequest, slog.LevelDebug},
+		{http.MethodPut, LevelPattern + "?level=warn&ttl=soon", http.StatusBadRequest, slog.LevelDebug},
+		{http.MethodPost, LevelPattern, http.StatusMethodNotAllowed, slog.LevelDebug},
+		{http.MethodPut, LevelPattern, http.StatusOK, slog.LevelInfo},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		control.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+		if w.Code != tt.code {
+			t.Errorf("%s %s: expected code %d, got %d", tt.method, tt.target, tt.code, w.Code)
+		}
+		if level.Level() != tt.level {
+			t.Errorf("%s %s: expected level %v, got %v", tt.method, tt.target, tt.level, level.Level())
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+
+		var state LevelState
+		if err := json.NewDecoder(w.Body).Decode(&state); err != nil {
+			t.Fatal(err)
+		}
+		if got := state.Loggers[DefaultLevelName]; got != tt.level.String() {
+			t.Errorf("%s %s: expected state level %v, got %s", tt.method, tt.target, tt.level, got)
+		}
+	}
+}
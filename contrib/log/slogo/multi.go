@@ -0,0 +1,165 @@
+package slogo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// UnnamedHandlerName is the name selecting the handlers registered without
+// a name, like the slog.Handler values of GroupSlogHandler.
+const UnnamedHandlerName = "unnamed"
+
+// Handler is a named handler, selected by name in the Handlers of the
+// logger config.
+type Handler struct {
+	Name    string
+	Handler slog.Handler
+
+	// Level, if not nil, is the minimum level of the records a
+	// MultiHandler dispatches to Handler, on top of the one of Handler.
+	Level slog.Leveler
+}
+
+// MultiHandler dispatches the records to every handler enabled for their
+// level, isolating the handlers from the errors of the others.
+type MultiHandler struct {
+	handlers []Handler
+}
+
+var _ slog.Handler = &MultiHandler{}
+
+// NewMultiHandler returns a handler dispatching to handlers.
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	named := make([]Handler, len(handlers))
+	for i, handler := range handlers {
+		named[i] = Handler{Handler: handler}
+	}
+	return &MultiHandler{handlers: named}
+}
+
+// NewLevelMultiHandler returns a handler dispatching to handlers, with
+// their levels.
+func NewLevelMultiHandler(handlers ...Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
+}
+
+// Handlers returns the handlers records are dispatched to.
+func (h *MultiHandler) Handlers() []slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.Handler
+	}
+	return handlers
+}
+
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes a clone of r to every handler enabled for its level, and
+// returns their errors joined.
+func (h *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handle(ctx, handler.Handler, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := slices.Clone(h.handlers)
+	for i, handler := range handlers {
+		handlers[i].Handler = handler.Handler.WithAttrs(attrs)
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	handlers := slices.Clone(h.handlers)
+	for i, handler := range handlers {
+		handlers[i].Handler = handler.Handler.WithGroup(name)
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+// enabled reports whether level passes the level of h and its handler.
+func (h Handler) enabled(ctx context.Context, level slog.Level) bool {
+	if h.Level != nil && level < h.Level.Level() {
+		return false
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+// handle calls handler, returning its panic as an error.
+func handle(ctx context.Context, handler slog.Handler, r slog.Record) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("slog handler %T panicked: %v", handler, p)
+		}
+	}()
+	return handler.Handle(ctx, r)
+}
+
+// SelectHandlers returns the handlers of configs, in order, with their
+// level, or all the handlers sorted by name if configs is empty. The
+// handlers named UnnamedHandlerName are selected together. It returns an
+// error for the names without handler or with several, and for the
+// invalid levels.
+func SelectHandlers(handlers []Handler, configs []logger.HandlerConfig) ([]Handler, error) {
+	byName := make(map[string][]Handler, len(handlers))
+	for _, h := range handlers {
+		if _, ok := byName[h.Name]; ok && h.Name != UnnamedHandlerName {
+			return nil, fmt.Errorf("slog handler %q registered twice", h.Name)
+		}
+		byName[h.Name] = append(byName[h.Name], h)
+	}
+
+	if len(configs) == 0 {
+		for _, name := range sortedNames(byName) {
+			configs = append(configs, logger.HandlerConfig{Name: name})
+		}
+	}
+
+	selected := make([]Handler, 0, len(configs))
+	for _, c := range configs {
+		named, ok := byName[c.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown slog handler %q, registered: %v", c.Name, sortedNames(byName))
+		}
+		for _, h := range named {
+			if c.Level != "" {
+				level, err := ParseLevel(c.Level)
+				if err != nil {
+					return nil, fmt.Errorf("level of slog handler %q: %w", c.Name, err)
+				}
+				h.Level = level
+			}
+			selected = append(selected, h)
+		}
+	}
+	return selected, nil
+}
+
+func sortedNames(handlers map[string][]Handler) []string {
+	names := make([]string, 0, len(handlers))
+	for name := range handlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
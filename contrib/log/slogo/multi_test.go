@@ -0,0 +1,108 @@
+package slogo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// failingHandler is a Handler just for testing that fails or panics on
+// each record.
+type failingHandler struct {
+	testHandler
+	panics bool
+}
+
+func (h *failingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.panics {
+		panic("boom")
+	}
+	return errors.New("failed")
+}
+
+func TestMultiHandler(t *testing.T) {
+	var info, debug bytes.Buffer
+	handler := NewMultiHandler(
+		slog.NewTextHandler(&info, nil),
+		&failingHandler{},
+		&failingHandler{panics: true},
+		slog.NewTextHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+
+	log := WithName(slog.New(handler), "orders")
+	log.Debug("details")
+	log.Info("summary")
+
+	if out := info.String(); strings.Contains(out, "details") || !strings.Contains(out, "msg=summary logger=orders") {
+		t.Errorf("expected info handler to get the info record only, got %q", out)
+	}
+	if out := debug.String(); !strings.Contains(out, "msg=details") || !strings.Contains(out, "msg=summary") {
+		t.Errorf("expected debug handler to get both records, got %q", out)
+	}
+
+	err := handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0))
+	if err == nil || !strings.Contains(err.Error(), "failed") || !strings.Contains(err.Error(), "panicked: boom") {
+		t.Errorf("expected joined handler errors, got %v", err)
+	}
+}
+
+func TestMultiHandlerLevels(t *testing.T) {
+	var all, warn bytes.Buffer
+	handler := NewLevelMultiHandler(
+		Handler{Handler: slog.NewTextHandler(&all, nil)},
+		Handler{Handler: slog.NewTextHandler(&warn, nil), Level: slog.LevelWarn},
+	)
+
+	log := slog.New(handler)
+	log.Info("summary")
+	log.Warn("careful")
+
+	if out := all.String(); !strings.Contains(out, "msg=summary") || !strings.Contains(out, "msg=careful") {
+		t.Errorf("expected handler without level to get both records, got %q", out)
+	}
+	if out := warn.String(); strings.Contains(out, "msg=summary") || !strings.Contains(out, "msg=careful") {
+		t.Errorf("expected warn handler to get the warn record only, got %q", out)
+	}
+}
+
+func TestSelectHandlers(t *testing.T) {
+	a, b := &testHandler{}, &testHandler{}
+	u1, u2 := &testHandler{}, &testHandler{}
+	handlers := []Handler{
+		{Name: "b", Handler: b},
+		{Name: "a", Handler: a},
+		{Name: UnnamedHandlerName, Handler: u1},
+		{Name: UnnamedHandlerName, Handler: u2},
+	}
+
+	selected, err := SelectHandlers(handlers, nil)
+	if err != nil || len(selected) != 4 || selected[0].Handler != a || selected[1].Handler != b {
+		t.Errorf("expected all handlers by name, got %v %v", selected, err)
+	}
+
+	selected, err = SelectHandlers(handlers, []logger.HandlerConfig{{Name: "b", Level: "warn"}})
+	if err != nil || len(selected) != 1 || selected[0].Handler != b || selected[0].Level != slog.LevelWarn {
+		t.Errorf("expected the listed handler with its level, got %v %v", selected, err)
+	}
+
+	selected, err = SelectHandlers(handlers, []logger.HandlerConfig{{Name: UnnamedHandlerName}})
+	if err != nil || len(selected) != 2 || selected[0].Handler != u1 || selected[1].Handler != u2 {
+		t.Errorf("expected the unnamed handlers, got %v %v", selected, err)
+	}
+
+	if _, err := SelectHandlers(handlers, []logger.HandlerConfig{{Name: "c"}}); err == nil {
+		t.Error("expected an error for an unknown handler")
+	}
+	if _, err := SelectHandlers(handlers, []logger.HandlerConfig{{Name: "a", Level: "loud"}}); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+	if _, err := SelectHandlers(append(handlers, Handler{Name: "a"}), nil); err == nil {
+		t.Error("expected an error for a handler registered twice")
+	}
+}
@@ -0,0 +1,83 @@
+package slogo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// NameLeveler returns the level of the loggers named name, or false if
+// their handler applies its own. LevelControl implements it.
+type NameLeveler interface {
+	NameLevel(name string) (slog.Level, bool)
+}
+
+// NameHandler enforces the levels of the loggers named with WithName, and
+// delegates the others to the handler it wraps.
+type NameHandler struct {
+	handler slog.Handler
+	levels  NameLeveler
+	name    string
+	grouped bool
+}
+
+var _ slog.Handler = &NameHandler{}
+
+// NewNameHandler returns a handler enforcing the levels of levels for the
+// named loggers of handler.
+func NewNameHandler(handler slog.Handler, levels NameLeveler) *NameHandler {
+	return &NameHandler{handler: handler, levels: levels}
+}
+
+// Handler returns the wrapped handler.
+func (h *NameHandler) Handler() slog.Handler {
+	return h.handler
+}
+
+func (h *NameHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.name != "" {
+		if min, ok := h.levels.NameLevel(h.name); ok {
+			return level >= min
+		}
+	}
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle passes r to the wrapped handler, which the standard handlers do
+// not filter by level again.
+func (h *NameHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *NameHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := *h
+	out.handler = h.handler.WithAttrs(attrs)
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == nameKey && a.Value.Kind() == slog.KindString {
+				out.name = cleanName(a.Value.String())
+			}
+		}
+	}
+	return &out
+}
+
+func (h *NameHandler) WithGroup(name string) slog.Handler {
+	out := *h
+	out.handler = h.handler.WithGroup(name)
+	out.grouped = out.grouped || name != ""
+	return &out
+}
+
+// ParseLevels parses the levels of the named loggers of a config.
+func ParseLevels(levels map[string]string) (map[string]slog.Level, error) {
+	parsed := make(map[string]slog.Level, len(levels))
+	for name, text := range levels {
+		level, err := ParseLevel(text)
+		if err != nil {
+			return nil, fmt.Errorf("level of %s: %w", name, err)
+		}
+		parsed[name] = level
+	}
+	return parsed, nil
+}
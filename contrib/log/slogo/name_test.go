@@ -0,0 +1,115 @@
+package slogo
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNameLevel(t *testing.T) {
+	control := NewLevelControl()
+	control.ConfigureNames(map[string]slog.Level{
+		"orders":     slog.LevelWarn,
+		"/orders/db": slog.LevelDebug,
+	})
+
+	tests := []struct {
+		name  string
+		level slog.Level
+		ok    bool
+	}{
+		{"orders", slog.LevelWarn, true},
+		{"orders/api", slog.LevelWarn, true},
+		{"orders/db", slog.LevelDebug, true},
+		{"orders/db/pool", slog.LevelDebug, true},
+		{"orders-db", 0, false},
+		{"billing", 0, false},
+	}
+	for _, tt := range tests {
+		level, ok := control.NameLevel(tt.name)
+		if ok != tt.ok || level != tt.level {
+			t.Errorf("%s: expected %v %v, got %v %v", tt.name, tt.level, tt.ok, level, ok)
+		}
+	}
+}
+
+func TestNameLevelOverride(t *testing.T) {
+	control := NewLevelControl()
+	control.ConfigureNames(map[string]slog.Level{"orders": slog.LevelWarn})
+
+	control.SetName("orders", slog.LevelDebug, 10*time.Millisecond)
+	if level, _ := control.NameLevel("orders/db"); level != slog.LevelDebug {
+		t.Errorf("expected overridden level, got %v", level)
+	}
+	if state := control.State(); state.Names["orders"] != "DEBUG" || state.NameExpires["orders"].IsZero() {
+		t.Errorf("expected override in state, got %+v", state)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if level, _ := control.NameLevel("orders"); level == slog.LevelWarn {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected level to revert")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	control.SetName("billing", slog.LevelError, 0)
+	control.Reset()
+	if _, ok := control.NameLevel("billing"); ok {
+		t.Error("expected override to be reset")
+	}
+}
+
+func TestNameHandler(t *testing.T) {
+	control := NewLevelControl()
+	control.ConfigureNames(map[string]slog.Level{
+		"orders/db": slog.LevelDebug,
+		"billing":   slog.LevelError,
+	})
+
+	var buf bytes.Buffer
+	log := slog.New(NewNameHandler(slog.NewTextHandler(&buf, nil), control))
+
+	WithName(log, "orders/db").Debug("query")
+	WithName(log, "orders").Debug("skipped")
+	WithName(log, "billing").Warn("skipped")
+	log.WithGroup("request").With(Name("orders/db")).Debug("skipped")
+	log.Info("request")
+
+	out := buf.String()
+	if !strings.Contains(out, "msg=query") || !strings.Contains(out, "msg=request") {
+		t.Errorf("expected enabled records, got %q", out)
+	}
+	if strings.Contains(out, "skipped") {
+		t.Errorf("expected disabled records to be skipped, got %q", out)
+	}
+
+	control.SetName("orders", slog.LevelDebug, 0)
+	buf.Reset()
+	WithName(log, "orders").Debug("enabled")
+	if !strings.Contains(buf.String(), "msg=enabled") {
+		t.Errorf("expected runtime override to apply, got %q", buf.String())
+	}
+}
+
+func TestLevelControlNameLeveler(t *testing.T) {
+	control := NewLevelControl()
+	levels := control.NameLeveler(map[string]slog.Level{"/orders/": slog.LevelWarn})
+
+	if level, ok := levels.NameLevel("orders/db"); !ok || level != slog.LevelWarn {
+		t.Errorf("NameLevel(orders/db) = %v, %t, want WARN", level, ok)
+	}
+	if _, ok := control.NameLevel("orders"); ok {
+		t.Error("expected the control to be left unchanged")
+	}
+
+	control.SetName("orders", slog.LevelDebug, 0)
+	if level, _ := levels.NameLevel("orders/db"); level != slog.LevelDebug {
+		t.Errorf("NameLevel(orders/db) = %v, want the DEBUG override", level)
+	}
+}
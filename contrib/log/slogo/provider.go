@@ -1,5 +1,9 @@
 package slogo
 
+// The logger dispatches to the Handler values of GroupHandlers and to the
+// slog.Handler values of GroupSlogHandler, named UnnamedHandlerName,
+// selected by the logger config.
 const (
 	GroupSlogHandler = "slog.Handler"
+	GroupHandlers    = "slog.Handlers"
 )
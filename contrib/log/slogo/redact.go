@@ -0,0 +1,186 @@
+package slogo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// Redactor redacts the sensitive values of attributes, by key and by value,
+// and the sensitive parts of messages, by value.
+type Redactor struct {
+	keys        []string // joined segments of the keys
+	values      []*regexp.Regexp
+	replacement string
+}
+
+// NewRedactor returns the redactor of config, with the default keys, and
+// the default values if enabled.
+func NewRedactor(config logger.Redaction) (*Redactor, error) {
+	r := &Redactor{replacement: config.Replacement}
+	if r.replacement == "" {
+		r.replacement = logger.DefaultRedactReplacement
+	}
+
+	for _, key := range slices.Concat(logger.DefaultRedactKeys, config.Keys) {
+		if segments := keySegments(key); len(segments) > 0 {
+			r.keys = append(r.keys, strings.Join(segments, ""))
+		}
+	}
+	values := config.Values
+	if config.DefaultValues {
+		values = slices.Concat(logger.DefaultRedactValues, values)
+	}
+	for _, value := range values {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("redact value %q: %w", value, err)
+		}
+		r.values = append(r.values, re)
+	}
+	return r, nil
+}
+
+// Attr returns a with its sensitive values redacted, resolving the
+// LogValuers and recursing into the groups. Errors and fmt.Stringers are
+// matched by their string, which replaces them if redacted.
+func (r *Redactor) Attr(a slog.Attr) slog.Attr {
+	if r.sensitiveKey(a.Key) {
+		return slog.String(a.Key, r.replacement)
+	}
+
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = r.Attr(attr)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindString:
+		a.Value = slog.StringValue(r.String(a.Value.String()))
+	case slog.KindAny:
+		if len(r.values) == 0 {
+			return a
+		}
+		var s string
+		switch v := a.Value.Any().(type) {
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		default:
+			return a
+		}
+		if redacted := r.String(s); redacted != s {
+			a.Value = slog.StringValue(redacted)
+		}
+	}
+	return a
+}
+
+// String returns s with the parts matching the value regexps redacted.
+func (r *Redactor) String(s string) string {
+	for _, re := range r.values {
+		s = re.ReplaceAllLiteralString(s, r.replacement)
+	}
+	return s
+}
+
+// sensitiveKey reports whether key holds the segments of one of the keys,
+// so that db_password and apiKey match password and api_key, but
+// max_tokens and secretary match neither token nor secret.
+func (r *Redactor) sensitiveKey(key string) bool {
+	segments := keySegments(key)
+	for i := range segments {
+		var joined string
+		for _, segment := range segments[i:] {
+			joined += segment
+			if slices.Contains(r.keys, joined) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keySegments returns the lowercase segments of key, separated by '_',
+// '-', '.', spaces or a change of case like in apiKey or APIKey.
+func keySegments(key string) []string {
+	var (
+		segments []string
+		segment  []rune
+	)
+	runes := []rune(key)
+	for i, c := range runes {
+		switch {
+		case c == '_' || c == '-' || c == '.' || unicode.IsSpace(c):
+			if len(segment) > 0 {
+				segments = append(segments, string(segment))
+			}
+			segment = segment[:0]
+			continue
+		case unicode.IsUpper(c) && len(segment) > 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			segments = append(segments, string(segment))
+			segment = segment[:0]
+		}
+		segment = append(segment, unicode.ToLower(c))
+	}
+	if len(segment) > 0 {
+		segments = append(segments, string(segment))
+	}
+	return segments
+}
+
+// RedactHandler redacts the sensitive values of the messages and the
+// attributes of the handler it wraps.
+type RedactHandler struct {
+	handler  slog.Handler
+	redactor *Redactor
+}
+
+var _ slog.Handler = &RedactHandler{}
+
+// NewRedactHandler returns a handler redacting the attributes of handler
+// with redactor.
+func NewRedactHandler(handler slog.Handler, redactor *Redactor) *RedactHandler {
+	return &RedactHandler{handler: handler, redactor: redactor}
+}
+
+// Handler returns the wrapped handler.
+func (h *RedactHandler) Handler() slog.Handler {
+	return h.handler
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.redactor.String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.Attr(a))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactor.Attr(a)
+	}
+	return &RedactHandler{handler: h.handler.WithAttrs(redacted), redactor: h.redactor}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{handler: h.handler.WithGroup(name), redactor: h.redactor}
+}
@@ -0,0 +1,160 @@
+package slogo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// credentials is a LogValuer just for testing.
+type credentials struct {
+	user, password string
+}
+
+func (c credentials) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("user", c.user), slog.String("password", c.password))
+}
+
+func TestRedactHandler(t *testing.T) {
+	redactor, err := NewRedactor(logger.Redaction{Enabled: true, Keys: []string{"session"}, DefaultValues: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	log := slog.New(NewRedactHandler(slog.NewJSONHandler(&buf, nil), redactor)).
+		With("Authorization", "Bearer abc")
+
+	log.WithGroup("request").Info("login",
+		slog.String("db_password", "hunter2"),
+		slog.String("session_id", "s1"),
+		slog.String("note", "mail alice@example.com, card 4111 1111 1111 1111"),
+		slog.Any("credentials", credentials{user: "alice", password: "hunter2"}),
+		slog.Group("client", slog.String("token", "t1"), slog.Int("port", 443)),
+	)
+
+	var got struct {
+		Authorization string
+		Request       struct {
+			DBPassword  string `json:"db_password"`
+			SessionID   string `json:"session_id"`
+			Note        string
+			Credentials struct{ User, Password string }
+			Client      struct {
+				Token string
+				Port  int
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	const redacted = logger.DefaultRedactReplacement
+	for _, v := range []string{got.Authorization, got.Request.DBPassword, got.Request.SessionID, got.Request.Credentials.Password, got.Request.Client.Token} {
+		if v != redacted {
+			t.Errorf("expected redacted value, got %q in %s", v, buf.String())
+		}
+	}
+	if want := "mail " + redacted + ", card " + redacted; got.Request.Note != want {
+		t.Errorf("expected note %q, got %q", want, got.Request.Note)
+	}
+	if got.Request.Credentials.User != "alice" || got.Request.Client.Port != 443 {
+		t.Errorf("expected other values to stay, got %s", buf.String())
+	}
+}
+
+func TestRedactHandlerMessagesAndAnyValues(t *testing.T) {
+	redactor, err := NewRedactor(logger.Redaction{Enabled: true, DefaultValues: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	slog.New(NewRedactHandler(slog.NewJSONHandler(&buf, nil), redactor)).Error("mail to alice@example.com failed",
+		Err(errors.New("no mailbox alice@example.com")),
+		slog.Any("to", address("alice@example.com")),
+		slog.Any("port", 25),
+	)
+
+	var got struct {
+		Msg   string
+		Error string
+		To    string
+		Port  int
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	const redacted = logger.DefaultRedactReplacement
+	if want := "mail to " + redacted + " failed"; got.Msg != want {
+		t.Errorf("expected message %q, got %q", want, got.Msg)
+	}
+	if want := "no mailbox " + redacted; got.Error != want {
+		t.Errorf("expected error %q, got %q", want, got.Error)
+	}
+	if got.To != redacted || got.Port != 25 {
+		t.Errorf("expected the stringer redacted and the port kept, got %s", buf.String())
+	}
+}
+
+// address is a fmt.Stringer just for testing.
+type address string
+
+func (a address) String() string {
+	return string(a)
+}
+
+func TestRedactorKeySegments(t *testing.T) {
+	redactor, err := NewRedactor(logger.Redaction{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"password":      true,
+		"db_password":   true,
+		"dbPassword":    true,
+		"DB-PASSWORD":   true,
+		"apiKey":        true,
+		"X-API-Key":     true,
+		"access.token":  true,
+		"Authorization": true,
+		"max_tokens":    false,
+		"secretary":     false,
+		"passwords_ok":  false,
+		"keyboard":      false,
+	}
+	for key, want := range tests {
+		if got := redactor.sensitiveKey(key); got != want {
+			t.Errorf("sensitiveKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestNewRedactorInvalidValue(t *testing.T) {
+	if _, err := NewRedactor(logger.Redaction{Values: []string{"("}}); err == nil {
+		t.Error("expected an error for an invalid regexp")
+	}
+}
+
+func TestRedactorMatchesKeysOnlyByDefault(t *testing.T) {
+	redactor, err := NewRedactor(logger.Redaction{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := redactor.Attr(slog.String("token", "t1")).Value.String(); got != logger.DefaultRedactReplacement {
+		t.Errorf("expected the token redacted, got %q", got)
+	}
+	for _, s := range []string{"order 1700000000000123", "mail alice@example.com"} {
+		if got := redactor.Attr(slog.String("note", s)).Value.String(); got != s {
+			t.Errorf("expected %q to stay without DefaultValues, got %q", s, got)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package slogo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// DefaultSamplingInterval is the sampling interval when not configured.
+const DefaultSamplingInterval = time.Second
+
+// SampleHandler samples the repeated records of the handler it wraps, by
+// level and message. The handlers derived with WithAttrs and WithGroup
+// share its counts.
+type SampleHandler struct {
+	handler slog.Handler
+	sampler *sampler
+}
+
+var _ slog.Handler = &SampleHandler{}
+
+// sampler counts the records of the current interval, and the dropped
+// ones since the last report.
+type sampler struct {
+	handler  slog.Handler
+	interval time.Duration
+	rule     logger.SamplingRule
+	levels   map[slog.Level]logger.SamplingRule
+	now      func() time.Time
+
+	mu      sync.Mutex
+	start   time.Time
+	counts  map[sampleKey]int
+	dropped map[slog.Level]int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type sampleKey struct {
+	level   slog.Level
+	message string
+}
+
+// NewSampleHandler returns a handler sampling the records of handler as set
+// by config, reporting the dropped records to handler until closed.
+func NewSampleHandler(handler slog.Handler, config logger.Sampling) (*SampleHandler, error) {
+	s := &sampler{
+		handler:  handler,
+		interval: config.Interval,
+		rule:     config.SamplingRule,
+		levels:   make(map[slog.Level]logger.SamplingRule, len(config.Levels)),
+		now:      time.Now,
+		counts:   make(map[sampleKey]int),
+		dropped:  make(map[slog.Level]int64),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if s.interval <= 0 {
+		s.interval = DefaultSamplingInterval
+	}
+	for name, rule := range config.Levels {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("sampling level %s: %w", name, err)
+		}
+		s.levels[level] = rule
+	}
+
+	if config.ReportInterval > 0 {
+		go s.reportEvery(config.ReportInterval)
+	} else {
+		close(s.done)
+	}
+	return &SampleHandler{handler: handler, sampler: s}, nil
+}
+
+// Handler returns the wrapped handler.
+func (h *SampleHandler) Handler() slog.Handler {
+	return h.handler
+}
+
+// Close stops the reports, after reporting the records dropped since the
+// last one.
+func (h *SampleHandler) Close() error {
+	select {
+	case <-h.sampler.stop:
+	default:
+		close(h.sampler.stop)
+	}
+	<-h.sampler.done
+	h.sampler.report()
+	return nil
+}
+
+func (h *SampleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *SampleHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.keep(r.Level, r.Message) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *SampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SampleHandler{handler: h.handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *SampleHandler) WithGroup(name string) slog.Handler {
+	return &SampleHandler{handler: h.handler.WithGroup(name), sampler: h.sampler}
+}
+
+// keep reports whether the record of level and message is kept, counting
+// it as dropped otherwise.
+func (s *sampler) keep(level slog.Level, message string) bool {
+	rule, ok := s.levels[level]
+	if !ok {
+		rule = s.rule
+	}
+	if rule.First <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now := s.now(); now.Sub(s.start) >= s.interval {
+		s.start = now
+		clear(s.counts)
+	}
+
+	key := sampleKey{level: level, message: message}
+	n := s.counts[key] + 1
+	s.counts[key] = n
+
+	if n <= rule.First || (rule.Thereafter > 0 && (n-rule.First)%rule.Thereafter == 0) {
+		return true
+	}
+	s.dropped[level]++
+	return false
+}
+
+func (s *sampler) reportEvery(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.report()
+		}
+	}
+}
+
+// report logs the count of the records dropped since the last report, if
+// any, by level.
+func (s *sampler) report() {
+	s.mu.Lock()
+	var (
+		total  int64
+		levels []any
+	)
+	for level, n := range s.dropped {
+		total += n
+		levels = append(levels, slog.Int64(level.String(), n))
+	}
+	clear(s.dropped)
+	s.mu.Unlock()
+
+	if total == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	if !s.handler.Enabled(ctx, slog.LevelWarn) {
+		return
+	}
+	r := slog.NewRecord(s.now(), slog.LevelWarn, "log records dropped by sampling", 0)
+	r.AddAttrs(slog.Int64("dropped", total), slog.Group("levels", levels...))
+	_ = s.handler.Handle(ctx, r)
+}
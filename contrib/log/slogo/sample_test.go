@@ -0,0 +1,68 @@
+package slogo
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-toho/toho/logger"
+)
+
+func TestSampleHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewSampleHandler(slog.NewTextHandler(&buf, nil), logger.Sampling{
+		Enabled:      true,
+		Interval:     time.Second,
+		SamplingRule: logger.SamplingRule{First: 2, Thereafter: 3},
+		Levels: map[string]logger.SamplingRule{
+			"error": {},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	handler.sampler.now = func() time.Time { return now }
+
+	log := slog.New(handler).With("component", "orders")
+	for i := 0; i < 8; i++ {
+		log.Info("repeated")
+		log.Error("failed")
+	}
+	log.Info("other")
+
+	// next interval
+	now = now.Add(time.Second)
+	log.Info("repeated")
+
+	out := buf.String()
+	// kept: 1, 2, 5, 8 then 1 of the next interval
+	if n := strings.Count(out, "msg=repeated"); n != 5 {
+		t.Errorf("expected 5 sampled records, got %d in %s", n, out)
+	}
+	if n := strings.Count(out, "msg=failed"); n != 8 {
+		t.Errorf("expected unsampled error records, got %d", n)
+	}
+	if !strings.Contains(out, "msg=other") {
+		t.Errorf("expected other messages to be counted apart, got %s", out)
+	}
+
+	buf.Reset()
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "dropped by sampling") || !strings.Contains(out, "dropped=4 levels.INFO=4") {
+		t.Errorf("expected dropped records report, got %q", out)
+	}
+}
+
+func TestSampleHandlerInvalidLevel(t *testing.T) {
+	_, err := NewSampleHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), logger.Sampling{
+		Levels: map[string]logger.SamplingRule{"loud": {}},
+	})
+	if err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+}
@@ -1,8 +1,10 @@
 package slogo
 
 import (
+	"io"
 	"log/slog"
 	"os"
+	"slices"
 
 	"github.com/go-toho/toho/logger"
 )
@@ -15,6 +17,17 @@ const (
 	errKey = "error"
 )
 
+// DefaultHandlerName is the name of the handler writing to the configured
+// output.
+const DefaultHandlerName = "default"
+
+// DefaultLevelName is the name of the levels of the handlers returned by
+// NewHandler, and FxLevelName the one of the Fx setup logger.
+const (
+	DefaultLevelName = "default"
+	FxLevelName      = "fx"
+)
+
 // ParseLevel parses a level based on the number or ASCII representation of the
 // log level. If the provided representation is invalid an error is returned.
 //
@@ -30,33 +43,101 @@ func NewDefault(handlers ...slog.Handler) (*slog.Logger, error) {
 	return New(*logger.DefaultConfig, handlers...)
 }
 
+// New returns a logger dispatching to handlers, or else to the handler of
+// config.
 func New(config logger.Config, handlers ...slog.Handler) (*slog.Logger, error) {
-	if len(handlers) == 0 || handlers[0] == nil {
+	handlers = slices.DeleteFunc(slices.Clone(handlers), func(h slog.Handler) bool { return h == nil })
+	switch len(handlers) {
+	case 0:
 		handler, err := NewHandler(config)
 		if err != nil {
 			return slog.Default(), err
 		}
 		return slog.New(handler), nil
+	case 1:
+		return slog.New(handlers[0]), nil
 	}
-	return slog.New(handlers[0]), nil
+	return slog.New(NewMultiHandler(handlers...)), nil
 }
 
+// NewHandler returns a handler for config, whose levels Levels overrides,
+// the configured ones of the named loggers included, redacting the sensitive values,
+// adding the context attributes, sampling the records and handling them in
+// the background if enabled. Its file output, if any, its sampling reports
+// and its background handling are never stopped: use logger.OpenOutput,
+// NewOutputHandler, NewSampleHandler and NewAsyncHandler to stop them.
 func NewHandler(config logger.Config) (slog.Handler, error) {
 	level, err := ParseLevel(config.Level)
 	if err != nil {
 		return nil, err
 	}
+	names, err := ParseLevels(config.Levels)
+	if err != nil {
+		return nil, err
+	}
+	out, err := logger.OpenOutput(config)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := NewOutputHandler(out, config, Levels.Leveler(level))
+	if config.Redact.Enabled {
+		redactor, err := NewRedactor(config.Redact)
+		if err != nil {
+			return nil, err
+		}
+		handler = NewRedactHandler(handler, redactor)
+	}
+	handler = NewNameHandler(handler, Levels.NameLeveler(names))
+	if config.Context {
+		handler = NewContextHandler(handler)
+	}
+	if config.Sampling.Enabled {
+		if handler, err = NewSampleHandler(handler, config.Sampling); err != nil {
+			return nil, err
+		}
+	}
+	if config.Async.Enabled {
+		if handler, err = NewAsyncHandler(handler, config.Async); err != nil {
+			return nil, err
+		}
+	}
+	return handler, nil
+}
+
+// NewLevelVar returns a level variable set to the configured level.
+func NewLevelVar(config logger.Config) (*slog.LevelVar, error) {
+	level, err := ParseLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(level)
+	return levelVar, nil
+}
+
+// NewLevelHandler returns a handler for config which reports level as its
+// minimum level, ignoring the configured one. Passing a *slog.LevelVar
+// allows to change the level at runtime. It writes to stdout, ignoring the
+// configured output.
+func NewLevelHandler(config logger.Config, level slog.Leveler) slog.Handler {
+	return NewOutputHandler(os.Stdout, config, level)
+}
 
+// NewOutputHandler returns a handler like NewLevelHandler writing to w, like
+// the output opened by logger.OpenOutput.
+func NewOutputHandler(w io.Writer, config logger.Config, level slog.Leveler) slog.Handler {
 	opts := &slog.HandlerOptions{
 		AddSource: config.Caller,
 		Level:     level,
 	}
 
 	if config.Format == logger.TextFormat {
-		return slog.NewTextHandler(os.Stdout, opts), nil
+		return slog.NewTextHandler(w, opts)
 	}
 
-	return slog.NewJSONHandler(os.Stdout, opts), nil
+	return slog.NewJSONHandler(w, opts)
 }
 
 // WithName returns a new Logger instance with the specified name element added
@@ -1,13 +1,22 @@
 package slogofx
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
-	"reflect"
+	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
 
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/contrib/core/debug"
 	"github.com/go-toho/toho/contrib/log/slogo"
 	slogofxevent "github.com/go-toho/toho/contrib/log/slogo/fxevent"
 	"github.com/go-toho/toho/logger"
@@ -15,8 +24,12 @@ import (
 )
 
 var Module = fx.Module("slog",
-	invokeHandlersCountCheck,
+	provideOutputs,
+	provideLevelControl,
+	provideLevelHandler,
+	provideLevelVar,
 	provideDefaultHandler,
+	provideLevelSubscriber,
 	provideLogger,
 	provideFxEventLogger,
 )
@@ -25,6 +38,9 @@ var FxPrinterLogger = fx.Provide(newLoggerPrinter)
 
 var FxEventLogger = fx.WithLogger(newFxEventLogger)
 
+// TrimDefaultHandler drops the default handler when others are provided.
+//
+// Deprecated: list the handlers in the Handlers of the logger config.
 var TrimDefaultHandler = trimDefaultHandler
 
 var SetAsDefaultLogger = fx.Invoke(setAsDefaultLogger)
@@ -36,50 +52,108 @@ func DecorateWithName(names ...string) fx.Option {
 }
 
 var (
-	invokeHandlersCountCheck = fx.Invoke(
+	provideOutputs = fx.Provide(newOutputs)
+
+	provideLevelControl = fx.Provide(
+		func(config logger.Config) (*slogo.LevelControl, error) {
+			names, err := slogo.ParseLevels(config.Levels)
+			if err != nil {
+				return nil, err
+			}
+			control := slogo.NewLevelControl()
+			control.ConfigureNames(names)
+			return control, nil
+		},
+	)
+
+	provideLevelHandler = fx.Provide(
 		fx.Annotate(
-			func(handlers []slog.Handler) {
-				if len(handlers) > 1 {
-					slog.Warn("found more that one slog handlers",
-						slog.Int("count", len(handlers)),
-					)
-				}
+			func(control *slogo.LevelControl) debug.Handler {
+				return debug.Handler{Pattern: slogo.LevelPattern, Handler: control}
 			},
-			fx.ParamTags(fxtags.Group(slogo.GroupSlogHandler)),
+			fx.ResultTags(fxtags.Group(debug.GroupHandlers)),
 		),
 	)
 
+	provideLevelVar = fx.Provide(
+		func(lifecycle fx.Lifecycle, config logger.Config, control *slogo.LevelControl) (*slog.LevelVar, error) {
+			level, err := slogo.NewLevelVar(config)
+			if err != nil {
+				return nil, err
+			}
+			lifecycle.Append(fx.StopHook(control.Track(slogo.DefaultLevelName, level)))
+			return level, nil
+		},
+	)
+
 	provideDefaultHandler = fx.Provide(
 		fx.Annotate(
-			func(config logger.Config) (slog.Handler, error) {
-				return slogo.NewHandler(config)
+			func(config logger.Config, level *slog.LevelVar, outputs *outputs) (slogo.Handler, error) {
+				out, err := outputs.open(config)
+				if err != nil {
+					return slogo.Handler{}, err
+				}
+				return slogo.Handler{
+					Name:    slogo.DefaultHandlerName,
+					Handler: slogo.NewOutputHandler(out, config, level),
+				}, nil
 			},
-			fx.ResultTags(fxtags.Group(slogo.GroupSlogHandler)),
+			fx.ResultTags(fxtags.Group(slogo.GroupHandlers)),
 		),
 	)
 
-	trimDefaultHandler = fx.Decorate(
+	provideLevelSubscriber = fx.Provide(
 		fx.Annotate(
-			func(handlers []slog.Handler) []slog.Handler {
-				if len(handlers) > 1 {
-					for _, h := range handlers {
-						if !strings.Contains(reflect.ValueOf(h).Type().String(), "*slog.") {
-							return []slog.Handler{h}
-						}
+			func(control *slogo.LevelControl, log *slog.Logger) config.Subscriber {
+				return config.Watch(func(_, new logger.Config) {
+					l, err := slogo.ParseLevel(new.Level)
+					if err != nil {
+						log.Warn("ignoring invalid log level", slog.String("level", new.Level), slogo.Err(err))
+						return
 					}
+					control.Configure(slogo.DefaultLevelName, l)
+					control.Configure(slogo.FxLevelName, l)
+
+					names, err := slogo.ParseLevels(new.Levels)
+					if err != nil {
+						log.Warn("ignoring invalid log levels", slogo.Err(err))
+						return
+					}
+					control.ConfigureNames(names)
+				})
+			},
+			fx.ResultTags(fxtags.Group(config.GroupConfigSubscribers)),
+		),
+	)
+
+	trimDefaultHandler = fx.Decorate(
+		fx.Annotate(
+			func(handlers []slogo.Handler, unnamed []slog.Handler) []slogo.Handler {
+				if len(handlers)+len(unnamed) > 1 {
+					return slices.DeleteFunc(handlers, func(h slogo.Handler) bool {
+						return h.Name == slogo.DefaultHandlerName
+					})
 				}
 				return handlers
 			},
-			fx.ParamTags(fxtags.Group(slogo.GroupSlogHandler)),
-			fx.ResultTags(fxtags.Group(slogo.GroupSlogHandler)),
+			fx.ParamTags(
+				fxtags.Group(slogo.GroupHandlers),
+				fxtags.Group(slogo.GroupSlogHandler),
+			),
+			fx.ResultTags(fxtags.Group(slogo.GroupHandlers)),
 		),
 	)
 
 	provideLogger = fx.Provide(
 		fx.Annotate(
-			slogo.New,
+			newLogger,
 			fx.ParamTags(
 				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Group(slogo.GroupHandlers),
 				fxtags.Group(slogo.GroupSlogHandler),
 			),
 		),
@@ -88,11 +162,95 @@ var (
 	provideFxEventLogger = fx.Provide(
 		fx.Annotate(
 			newSetupLoggerWrapper,
-			fx.ParamTags(fxtags.Named(logger.NamedFxSetupConfig)),
+			fx.ParamTags(fxtags.Empty, fxtags.Named(logger.NamedFxSetupConfig)),
 		),
 	)
 )
 
+// LevelOnSignal overrides the log levels with level for ttl whenever one of
+// the signals, like SIGUSR1, is received, or resets them if overridden.
+func LevelOnSignal(level slog.Level, ttl time.Duration, signals ...os.Signal) fx.Option {
+	return fx.Invoke(func(lifecycle fx.Lifecycle, control *slogo.LevelControl) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		lifecycle.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				control.ToggleOnSignal(ctx, level, ttl, signals...)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		})
+	})
+}
+
+// newLogger returns the logger dispatching to the handlers selected by the
+// config, the unnamed ones being named slogo.UnnamedHandlerName, or else to
+// the default handler of the config at level, with their levels, redacting the sensitive values ahead of each of them if enabled,
+// enforcing the levels of the named loggers, and adding the context
+// attributes, sampling the records and handling them in the background if
+// enabled.
+func newLogger(lifecycle fx.Lifecycle, config logger.Config, control *slogo.LevelControl, level *slog.LevelVar, outputs *outputs, handlers []slogo.Handler, unnamed []slog.Handler) (*slog.Logger, error) {
+	handlers = slices.Clone(handlers)
+	for _, h := range unnamed {
+		handlers = append(handlers, slogo.Handler{Name: slogo.UnnamedHandlerName, Handler: h})
+	}
+	selected, err := slogo.SelectHandlers(handlers, config.Handlers)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		out, err := outputs.open(config)
+		if err != nil {
+			return nil, err
+		}
+		selected = []slogo.Handler{{
+			Name:    slogo.DefaultHandlerName,
+			Handler: slogo.NewOutputHandler(out, config, level),
+		}}
+	}
+
+	var redactor *slogo.Redactor
+	if config.Redact.Enabled {
+		if redactor, err = slogo.NewRedactor(config.Redact); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, h := range selected {
+		if redactor != nil {
+			h.Handler = slogo.NewRedactHandler(h.Handler, redactor)
+		}
+		h.Handler = slogo.NewNameHandler(h.Handler, control)
+		selected[i] = h
+	}
+
+	var handler slog.Handler = slogo.NewLevelMultiHandler(selected...)
+	if config.Context {
+		handler = slogo.NewContextHandler(handler)
+	}
+	if config.Sampling.Enabled {
+		sampled, err := slogo.NewSampleHandler(handler, config.Sampling)
+		if err != nil {
+			return nil, err
+		}
+		lifecycle.Append(fx.StopHook(sampled.Close))
+		handler = sampled
+	}
+	if config.Async.Enabled {
+		// stops first, within the stop timeout of the app
+		async, err := slogo.NewAsyncHandler(handler, config.Async)
+		if err != nil {
+			return nil, err
+		}
+		lifecycle.Append(fx.StopHook(async.Stop))
+		handler = async
+	}
+	return slog.New(handler), nil
+}
+
 func setAsDefaultLogger(logger *slog.Logger) {
 	slog.SetDefault(logger)
 }
@@ -120,12 +278,59 @@ type setupLoggerWrapper struct {
 	*slog.Logger
 }
 
-func newSetupLoggerWrapper(config *logger.Config) (*setupLoggerWrapper, error) {
-	logger, err := slogo.New(*config, nil)
+func newSetupLoggerWrapper(lifecycle fx.Lifecycle, config *logger.Config, control *slogo.LevelControl, outputs *outputs) (*setupLoggerWrapper, error) {
+	level, err := slogo.NewLevelVar(*config)
+	if err != nil {
+		return nil, err
+	}
+	out, err := outputs.open(*config)
+	if err != nil {
+		return nil, err
+	}
+	lifecycle.Append(fx.StopHook(control.Track(slogo.FxLevelName, level)))
+	return &setupLoggerWrapper{Logger: slog.New(slogo.NewOutputHandler(out, *config, level))}, nil
+}
+
+// outputs are the outputs of the loggers of an app, opened once per
+// output, with the rotation of the first config, and closed on stop.
+type outputs struct {
+	mu      sync.Mutex
+	writers map[string]io.WriteCloser
+}
+
+func newOutputs(lifecycle fx.Lifecycle) *outputs {
+	o := &outputs{writers: make(map[string]io.WriteCloser)}
+	lifecycle.Append(fx.StopHook(o.close))
+	return o
+}
+
+func (o *outputs) open(config logger.Config) (io.Writer, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if out, ok := o.writers[config.Output]; ok {
+		return out, nil
+	}
+	out, err := logger.OpenOutput(config)
 	if err != nil {
 		return nil, err
 	}
-	return &setupLoggerWrapper{Logger: logger}, nil
+	o.writers[config.Output] = out
+	return out, nil
+}
+
+func (o *outputs) close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var errs []error
+	for name, out := range o.writers {
+		if err := out.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close log output %s: %w", name, err))
+		}
+		delete(o.writers, name)
+	}
+	return errors.Join(errs...)
 }
 
 func newSlogFxEventLogger(logger *slog.Logger) fxevent.Logger {
@@ -0,0 +1,6 @@
+package zapo
+
+// The logger tees the zapcore.Core values of GroupCores with its own.
+const (
+	GroupCores = "zap.Core"
+)
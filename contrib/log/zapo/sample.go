@@ -0,0 +1,172 @@
+package zapo
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// DefaultSamplingInterval is the sampling interval when not configured.
+const DefaultSamplingInterval = time.Second
+
+// SampleCore samples the repeated entries of the core it wraps, by level
+// and message, with the rule of their level. The cores derived with With
+// share its dropped counts.
+type SampleCore struct {
+	zapcore.Core
+	reporter *sampleReporter
+}
+
+// sampleReporter counts the dropped entries since the last report.
+type sampleReporter struct {
+	core zapcore.Core
+
+	mu      sync.Mutex
+	dropped map[zapcore.Level]int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSampleCore returns a core sampling the entries of core as set by
+// config, reporting the dropped entries to core every ReportInterval until
+// closed. Invalid levels are ignored.
+func NewSampleCore(core zapcore.Core, config logger.Sampling) *SampleCore {
+	r := &sampleReporter{
+		core:    core,
+		dropped: make(map[zapcore.Level]int64),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if config.ReportInterval > 0 {
+		go r.reportEvery(config.ReportInterval)
+	} else {
+		close(r.done)
+	}
+
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultSamplingInterval
+	}
+	hook := zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+		if dec&zapcore.LogDropped != 0 {
+			r.count(ent.Level)
+		}
+	})
+	sample := func(rule logger.SamplingRule) zapcore.Core {
+		if rule.First <= 0 {
+			return core
+		}
+		return zapcore.NewSamplerWithOptions(core, interval, rule.First, rule.Thereafter, hook)
+	}
+
+	levels := make(map[zapcore.Level]bool, len(config.Levels))
+	cores := make([]zapcore.Core, 0, len(config.Levels)+1)
+	for name, rule := range config.Levels {
+		level, err := ParseLevel(name)
+		if err != nil || levels[level] {
+			continue
+		}
+		levels[level] = true
+		cores = append(cores, &filterCore{
+			Core: sample(rule),
+			keep: func(l zapcore.Level) bool { return l == level },
+		})
+	}
+	if len(cores) == 0 {
+		return &SampleCore{Core: sample(config.SamplingRule), reporter: r}
+	}
+
+	cores = append(cores, &filterCore{
+		Core: sample(config.SamplingRule),
+		keep: func(l zapcore.Level) bool { return !levels[l] },
+	})
+	return &SampleCore{Core: zapcore.NewTee(cores...), reporter: r}
+}
+
+// Close stops the reports, after reporting the entries dropped since the
+// last one.
+func (c *SampleCore) Close() error {
+	r := c.reporter
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+	<-r.done
+	r.report()
+	return nil
+}
+
+func (r *sampleReporter) count(level zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropped[level]++
+}
+
+func (r *sampleReporter) reportEvery(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.report()
+		}
+	}
+}
+
+// report logs the count of the entries dropped since the last report, if
+// any, by level.
+func (r *sampleReporter) report() {
+	r.mu.Lock()
+	var (
+		total  int64
+		levels []zap.Field
+	)
+	for level, n := range r.dropped {
+		total += n
+		levels = append(levels, zap.Int64(level.String(), n))
+	}
+	clear(r.dropped)
+	r.mu.Unlock()
+
+	if total == 0 {
+		return
+	}
+
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: "log records dropped by sampling"}
+	if ce := r.core.Check(ent, nil); ce != nil {
+		ce.Write(zap.Int64("dropped", total), zap.Dict("levels", levels...))
+	}
+}
+
+// filterCore is a core for the entries of the levels kept only.
+type filterCore struct {
+	zapcore.Core
+	keep func(zapcore.Level) bool
+}
+
+func (c *filterCore) Enabled(level zapcore.Level) bool {
+	return c.keep(level) && c.Core.Enabled(level)
+}
+
+func (c *filterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &filterCore{Core: c.Core.With(fields), keep: c.keep}
+}
+
+func (c *filterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.keep(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,88 @@
+// Package zapo builds zap loggers from the logger config.
+//
+// Unlike slogo, it ignores the Levels, Context, Redact, Async and Handlers
+// settings of the config.
+//
+// WARNING: zapo does not redact. With Redact enabled, the passwords, tokens
+// and other sensitive values a slogo logger redacts are written as is by a
+// zapo logger of the same config.
+package zapo
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/go-toho/toho/logger"
+)
+
+// ParseLevel parses a level based on the ASCII representation of the log
+// level. If the provided representation is invalid an error is returned.
+func ParseLevel(level string) (zapcore.Level, error) {
+	return zapcore.ParseLevel(level)
+}
+
+func NewDefault(opts ...zap.Option) (*zap.Logger, error) {
+	return New(*logger.DefaultConfig, opts...)
+}
+
+// New returns a logger for config. Its file output, if any, stays open:
+// use logger.OpenOutput and NewCore to close it.
+func New(config logger.Config, opts ...zap.Option) (*zap.Logger, error) {
+	level, err := NewLevel(config)
+	if err != nil {
+		return nil, err
+	}
+	out, err := logger.OpenOutput(config)
+	if err != nil {
+		return nil, err
+	}
+
+	core := NewSampler(NewCore(zapcore.AddSync(out), config, level), config.Sampling)
+	return zap.New(core, append(Options(config), opts...)...), nil
+}
+
+// NewLevel returns an atomic level set to the configured level, which
+// changes the level at runtime.
+func NewLevel(config logger.Config) (zap.AtomicLevel, error) {
+	level, err := ParseLevel(config.Level)
+	if err != nil {
+		return zap.AtomicLevel{}, err
+	}
+	return zap.NewAtomicLevelAt(level), nil
+}
+
+// NewCore returns a core for config writing to w, with level as its minimum
+// level, ignoring the configured one.
+func NewCore(w zapcore.WriteSyncer, config logger.Config, level zapcore.LevelEnabler) zapcore.Core {
+	if config.Format == logger.TextFormat {
+		return zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), w, level)
+	}
+
+	encoder := zap.NewProductionEncoderConfig()
+	encoder.EncodeTime = zapcore.ISO8601TimeEncoder
+	return zapcore.NewCore(zapcore.NewJSONEncoder(encoder), w, level)
+}
+
+// Options returns the logger options of config.
+func Options(config logger.Config) []zap.Option {
+	if config.Caller {
+		return []zap.Option{zap.AddCaller()}
+	}
+	return nil
+}
+
+// NewSampler returns core sampled as set by config, see NewSampleCore, if
+// config is enabled. Its reports are never stopped: use NewSampleCore to
+// stop them.
+func NewSampler(core zapcore.Core, config logger.Sampling) zapcore.Core {
+	if !config.Enabled {
+		return core
+	}
+	return NewSampleCore(core, config)
+}
+
+// WithName returns a new Logger instance with the specified name element
+// added to the Logger's name, joined by a period like zap's Named.
+func WithName(l *zap.Logger, name string) *zap.Logger {
+	return l.Named(name)
+}
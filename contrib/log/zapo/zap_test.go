@@ -0,0 +1,94 @@
+package zapo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/go-toho/toho/logger"
+)
+
+func TestNewCore(t *testing.T) {
+	var buf bytes.Buffer
+	config := logger.Config{Level: "warn", Format: logger.JSONFormat}
+
+	level, err := NewLevel(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := WithName(zap.New(NewCore(zapcore.AddSync(&buf), config, level)), "orders/db")
+
+	log.Info("skipped")
+	log.Warn("kept", zap.Int("attempt", 2))
+	level.SetLevel(zapcore.InfoLevel)
+	log.Info("enabled")
+
+	out := buf.String()
+	if strings.Contains(out, "skipped") {
+		t.Errorf("expected info record to be skipped, got %s", out)
+	}
+	if !strings.Contains(out, `"logger":"orders/db","msg":"kept","attempt":2`) {
+		t.Errorf("expected named JSON record, got %s", out)
+	}
+	if !strings.Contains(out, `"msg":"enabled"`) {
+		t.Errorf("expected level change to apply, got %s", out)
+	}
+
+	if _, err := NewLevel(logger.Config{Level: "loud"}); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+}
+
+func TestNewSampler(t *testing.T) {
+	var buf bytes.Buffer
+	core := NewCore(zapcore.AddSync(&buf), logger.Config{Format: logger.TextFormat}, zapcore.DebugLevel)
+	log := zap.New(NewSampler(core, logger.Sampling{
+		Enabled:      true,
+		Interval:     time.Minute,
+		SamplingRule: logger.SamplingRule{First: 2, Thereafter: 3},
+		Levels: map[string]logger.SamplingRule{
+			"error": {},
+		},
+	}))
+
+	for i := 0; i < 8; i++ {
+		log.Info("repeated")
+		log.Error("failed")
+	}
+
+	out := buf.String()
+	// kept: 1, 2, 5, 8
+	if n := strings.Count(out, "repeated"); n != 4 {
+		t.Errorf("expected 4 sampled records, got %d in %s", n, out)
+	}
+	if n := strings.Count(out, "failed"); n != 8 {
+		t.Errorf("expected unsampled error records, got %d", n)
+	}
+}
+
+func TestSampleCoreReportsDropped(t *testing.T) {
+	var buf bytes.Buffer
+	core := NewCore(zapcore.AddSync(&buf), logger.Config{}, zapcore.DebugLevel)
+	sampled := NewSampleCore(core, logger.Sampling{
+		Enabled:      true,
+		Interval:     time.Minute,
+		SamplingRule: logger.SamplingRule{First: 1},
+	})
+
+	log := zap.New(sampled).With(zap.String("component", "orders"))
+	for i := 0; i < 5; i++ {
+		log.Info("repeated")
+	}
+	if err := sampled.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"log records dropped by sampling","dropped":4,"levels":{"info":4}`) {
+		t.Errorf("expected the dropped records report, got %s", out)
+	}
+}
@@ -0,0 +1,177 @@
+package zapofx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/contrib/log/zapo"
+	"github.com/go-toho/toho/logger"
+	"github.com/go-toho/toho/pkg/fxtags"
+)
+
+var Module = fx.Module("zap",
+	provideOutputs,
+	provideLevel,
+	provideLevelSubscriber,
+	provideLogger,
+	provideFxEventLogger,
+)
+
+var FxEventLogger = fx.WithLogger(newFxEventLogger)
+
+var SetAsDefaultLogger = fx.Invoke(setAsDefaultLogger)
+
+func DecorateWithName(names ...string) fx.Option {
+	return fx.Decorate(func(log *zap.Logger) *zap.Logger {
+		for _, name := range names {
+			log = zapo.WithName(log, name)
+		}
+		return log
+	})
+}
+
+var (
+	provideOutputs = fx.Provide(newOutputs)
+
+	provideLevel = fx.Provide(zapo.NewLevel)
+
+	provideLevelSubscriber = fx.Provide(
+		fx.Annotate(
+			func(level zap.AtomicLevel, log *zap.Logger) config.Subscriber {
+				return config.Watch(func(_, new logger.Config) {
+					l, err := zapo.ParseLevel(new.Level)
+					if err != nil {
+						log.Warn("ignoring invalid log level", zap.String("level", new.Level), zap.Error(err))
+						return
+					}
+					level.SetLevel(l)
+				})
+			},
+			fx.ResultTags(fxtags.Group(config.GroupConfigSubscribers)),
+		),
+	)
+
+	provideLogger = fx.Provide(
+		fx.Annotate(
+			newLogger,
+			fx.ParamTags(
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Empty,
+				fxtags.Group(zapo.GroupCores),
+			),
+		),
+	)
+
+	provideFxEventLogger = fx.Provide(
+		fx.Annotate(
+			newSetupLoggerWrapper,
+			fx.ParamTags(fxtags.Named(logger.NamedFxSetupConfig)),
+		),
+	)
+)
+
+// newLogger returns the logger teeing the core of the config output with
+// cores, sampled if enabled, and synced on stop, after reporting the
+// entries dropped by sampling.
+func newLogger(lifecycle fx.Lifecycle, config logger.Config, level zap.AtomicLevel, outputs *outputs, cores []zapcore.Core) (*zap.Logger, error) {
+	out, err := outputs.open(config)
+	if err != nil {
+		return nil, err
+	}
+
+	core := zapcore.NewTee(append([]zapcore.Core{zapo.NewCore(out, config, level)}, cores...)...)
+	var sampled *zapo.SampleCore
+	if config.Sampling.Enabled {
+		sampled = zapo.NewSampleCore(core, config.Sampling)
+		core = sampled
+	}
+	log := zap.New(core, zapo.Options(config)...)
+
+	lifecycle.Append(fx.StopHook(func() {
+		// syncing stdout fails on some platforms
+		_ = log.Sync()
+	}))
+	if sampled != nil {
+		// stops first, reporting ahead of the sync
+		lifecycle.Append(fx.StopHook(sampled.Close))
+	}
+	return log, nil
+}
+
+func setAsDefaultLogger(logger *zap.Logger) {
+	zap.ReplaceGlobals(logger)
+}
+
+type setupLoggerWrapper struct {
+	*zap.Logger
+}
+
+func newSetupLoggerWrapper(config *logger.Config, outputs *outputs) (*setupLoggerWrapper, error) {
+	level, err := zapo.NewLevel(*config)
+	if err != nil {
+		return nil, err
+	}
+	out, err := outputs.open(*config)
+	if err != nil {
+		return nil, err
+	}
+	return &setupLoggerWrapper{Logger: zap.New(zapo.NewCore(out, *config, level), zapo.Options(*config)...)}, nil
+}
+
+func newFxEventLogger(logger *setupLoggerWrapper) fxevent.Logger {
+	zapLogger := &fxevent.ZapLogger{Logger: logger.Logger}
+	zapLogger.UseLogLevel(zapcore.DebugLevel)
+	return zapLogger
+}
+
+// outputs are the outputs of the loggers of an app, opened once per
+// output, with the rotation of the first config, and closed on stop.
+type outputs struct {
+	mu      sync.Mutex
+	writers map[string]io.WriteCloser
+}
+
+func newOutputs(lifecycle fx.Lifecycle) *outputs {
+	o := &outputs{writers: make(map[string]io.WriteCloser)}
+	lifecycle.Append(fx.StopHook(o.close))
+	return o
+}
+
+func (o *outputs) open(config logger.Config) (zapcore.WriteSyncer, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if out, ok := o.writers[config.Output]; ok {
+		return zapcore.AddSync(out), nil
+	}
+	out, err := logger.OpenOutput(config)
+	if err != nil {
+		return nil, err
+	}
+	o.writers[config.Output] = out
+	return zapcore.AddSync(out), nil
+}
+
+func (o *outputs) close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var errs []error
+	for name, out := range o.writers {
+		if err := out.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close log output %s: %w", name, err))
+		}
+		delete(o.writers, name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package zapofx_test
+
+import (
+	"testing"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/go-toho/toho"
+	"github.com/go-toho/toho/contrib/log/zapo/zapofx"
+	"github.com/go-toho/toho/tohofx"
+)
+
+func TestPopulatesAppLogger(t *testing.T) {
+	var named *zap.Logger
+	a := toho.NewL[*zap.Logger](
+		toho.AppCore(tohofx.NewCore()),
+		toho.Options(
+			fx.NopLogger,
+			fx.Module("orders",
+				zapofx.DecorateWithName("orders", "db"),
+				fx.Populate(&named),
+			),
+		),
+	)
+
+	if err := a.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() {
+		if err := a.Stop(); err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	}()
+
+	if a.Logger() == nil {
+		t.Fatal("expected the app logger to be populated")
+	}
+	if got := named.Name(); got != "orders.db" {
+		t.Errorf("expected decorated logger name orders.db, got %q", got)
+	}
+}
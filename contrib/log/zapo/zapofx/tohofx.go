@@ -0,0 +1,13 @@
+package zapofx
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/go-toho/toho/tohofx"
+)
+
+func init() {
+	tohofx.Add("log/zap", func() fx.Option {
+		return Module
+	})
+}
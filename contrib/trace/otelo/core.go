@@ -0,0 +1,196 @@
+package otelo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-toho/toho"
+)
+
+// Span names of the traced operations and their phases.
+const (
+	SpanStart = "toho.start"
+	SpanStop  = "toho.stop"
+
+	PhaseInit  = "init"
+	PhaseStart = "start"
+	PhaseStop  = "stop"
+)
+
+// Option is a tracing core option.
+type Option func(o *options)
+
+// options is a tracing core options.
+type options struct {
+	config   Config
+	exporter sdktrace.SpanExporter
+	provider trace.TracerProvider
+}
+
+// WithConfig with tracing config.
+func WithConfig(config Config) Option {
+	return func(o *options) { o.config = config }
+}
+
+// WithExporter with span exporter, it takes precedence over the exporter
+// selected by the config.
+func WithExporter(exporter sdktrace.SpanExporter) Option {
+	return func(o *options) { o.exporter = exporter }
+}
+
+// WithTracerProvider with tracer provider, it takes precedence over the
+// config and the exporter. The provider is not shut down by the core.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) { o.provider = provider }
+}
+
+// Core wraps a toho.Core and traces its lifecycle: every Start and Stop is
+// recorded as a separate trace with one span per phase.
+type Core struct {
+	core   toho.Core
+	opts   options
+	tracer *Tracer
+
+	provider    trace.TracerProvider
+	sdk         *sdktrace.TracerProvider
+	stopTimeout time.Duration
+}
+
+// verify that Core implements the toho.Core interface.
+var _ toho.Core = (*Core)(nil)
+
+// WrapCore returns core with tracing of its lifecycle.
+func WrapCore(core toho.Core, opts ...Option) *Core {
+	o := options{
+		config: *DefaultConfig,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Core{
+		core:   core,
+		opts:   o,
+		tracer: NewTracer(nil),
+	}
+}
+
+// Tracer returns the tracer recording the lifecycle.
+func (c *Core) Tracer() *Tracer {
+	return c.tracer
+}
+
+// TracerProvider returns the provider used for the lifecycle spans.
+// It is only available after Init.
+func (c *Core) TracerProvider() trace.TracerProvider {
+	return c.provider
+}
+
+func (c *Core) Init(opts *toho.CoreOptions) error {
+	if err := c.setup(opts); err != nil {
+		return err
+	}
+
+	c.tracer.Begin(context.Background(), SpanStart)
+	c.tracer.BeginPhase(PhaseInit)
+
+	err := c.core.Init(opts)
+	c.tracer.EndPhase(err)
+
+	if err != nil {
+		c.tracer.End(err)
+		return errors.Join(err, c.shutdown(context.Background()))
+	}
+
+	return nil
+}
+
+func (c *Core) Start(ctx context.Context) error {
+	c.tracer.BeginPhase(PhaseStart)
+
+	err := c.core.Start(ctx)
+	c.tracer.End(err)
+
+	return errors.Join(err, c.flush(ctx))
+}
+
+func (c *Core) Stop(ctx context.Context) error {
+	c.tracer.Begin(ctx, SpanStop)
+	c.tracer.BeginPhase(PhaseStop)
+
+	err := c.core.Stop(ctx)
+	c.tracer.End(err)
+
+	return errors.Join(err, c.shutdown(ctx))
+}
+
+func (c *Core) Wait() <-chan os.Signal {
+	return c.core.Wait()
+}
+
+func (c *Core) setup(opts *toho.CoreOptions) error {
+	c.stopTimeout = opts.StopTimeout
+
+	switch {
+	case c.opts.provider != nil:
+		c.provider = c.opts.provider
+	case !c.opts.config.Enabled:
+		c.provider = nil
+	default:
+		exporter := c.opts.exporter
+		if exporter == nil {
+			var err error
+			exporter, err = NewExporter(context.Background(), c.opts.config)
+			if err != nil {
+				return err
+			}
+		}
+
+		sdk, err := NewTracerProvider(&opts.App, exporter)
+		if err != nil {
+			return err
+		}
+
+		c.sdk = sdk
+		c.provider = sdk
+	}
+
+	c.tracer.SetTracerProvider(c.provider)
+	return nil
+}
+
+func (c *Core) flush(ctx context.Context) error {
+	if c.sdk == nil {
+		return nil
+	}
+
+	ctx, cancel := c.timeoutContext(ctx)
+	defer cancel()
+
+	return c.sdk.ForceFlush(ctx)
+}
+
+func (c *Core) shutdown(ctx context.Context) error {
+	if c.sdk == nil {
+		return nil
+	}
+
+	ctx, cancel := c.timeoutContext(ctx)
+	defer cancel()
+
+	return c.sdk.Shutdown(ctx)
+}
+
+func (c *Core) timeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = context.WithoutCancel(ctx)
+	if c.stopTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.stopTimeout)
+}
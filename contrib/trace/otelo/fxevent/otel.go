@@ -0,0 +1,119 @@
+package fxevent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx/fxevent"
+
+	"github.com/go-toho/toho/contrib/trace/otelo"
+)
+
+// SpanLogger is an Fx event logger that records constructors, invokes and
+// lifecycle hooks as spans of the current otelo.Tracer phase.
+type SpanLogger struct {
+	Tracer *otelo.Tracer
+
+	// Next receives every event after it is recorded, if set.
+	Next fxevent.Logger
+
+	mu        sync.Mutex
+	invoke    trace.Span
+	invokeCtx context.Context
+}
+
+var _ fxevent.Logger = (*SpanLogger)(nil)
+
+// LogEvent records the given event and forwards it to the next logger.
+func (l *SpanLogger) LogEvent(event fxevent.Event) {
+	switch e := event.(type) {
+	case *fxevent.Invoking:
+		l.startInvoke(e)
+	case *fxevent.Invoked:
+		l.endInvoke(e)
+	case *fxevent.Run:
+		l.recordSpan(l.runContext(), e.Kind+" "+e.Name, e.Runtime, e.Err,
+			attribute.String("fx.kind", e.Kind),
+			moduleAttr(e.ModuleName),
+		)
+	case *fxevent.OnStartExecuted:
+		l.recordSpan(l.Tracer.Context(), "OnStart "+e.FunctionName, e.Runtime, e.Err,
+			attribute.String("fx.caller", e.CallerName),
+		)
+	case *fxevent.OnStopExecuted:
+		l.recordSpan(l.Tracer.Context(), "OnStop "+e.FunctionName, e.Runtime, e.Err,
+			attribute.String("fx.caller", e.CallerName),
+		)
+	case *fxevent.RollingBack:
+		l.addEvent("rolling back", e.StartErr)
+	case *fxevent.Stopping:
+		l.addEvent("received signal", nil,
+			attribute.String("signal", e.Signal.String()),
+		)
+	}
+
+	if l.Next != nil {
+		l.Next.LogEvent(event)
+	}
+}
+
+func (l *SpanLogger) startInvoke(e *fxevent.Invoking) {
+	ctx, span := l.Tracer.Start(l.Tracer.Context(), "invoke "+e.FunctionName,
+		trace.WithAttributes(moduleAttr(e.ModuleName)),
+	)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.invoke, l.invokeCtx = span, ctx
+}
+
+func (l *SpanLogger) endInvoke(e *fxevent.Invoked) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.invoke == nil {
+		return
+	}
+
+	otelo.EndSpan(l.invoke, e.Err)
+	l.invoke, l.invokeCtx = nil, nil
+}
+
+// runContext returns the parent of constructor spans: the invoke that
+// requested them, or the current phase.
+func (l *SpanLogger) runContext() context.Context {
+	l.mu.Lock()
+	ctx := l.invokeCtx
+	l.mu.Unlock()
+
+	if ctx == nil {
+		return l.Tracer.Context()
+	}
+	return ctx
+}
+
+// recordSpan records a span that ended now and lasted runtime.
+func (l *SpanLogger) recordSpan(ctx context.Context, name string, runtime time.Duration, err error, attrs ...attribute.KeyValue) {
+	end := time.Now()
+	_, span := l.Tracer.Start(ctx, name,
+		trace.WithTimestamp(end.Add(-runtime)),
+		trace.WithAttributes(attrs...),
+	)
+	otelo.EndSpan(span, err, trace.WithTimestamp(end))
+}
+
+func (l *SpanLogger) addEvent(name string, err error, attrs ...attribute.KeyValue) {
+	span := trace.SpanFromContext(l.Tracer.Context())
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+func moduleAttr(name string) attribute.KeyValue {
+	return attribute.String("fx.module", name)
+}
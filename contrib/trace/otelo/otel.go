@@ -0,0 +1,128 @@
+package otelo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/go-toho/toho/app"
+)
+
+// The span exporter can either be OTLP/HTTP, stdout or none.
+const (
+	OTLPHTTPExporter = "otlphttp"
+	StdoutExporter   = "stdout"
+	NoneExporter     = "none"
+)
+
+// Config stores the config for the tracer.
+type Config struct {
+	// Enabled exports the spans to the Exporter, only when set.
+	Enabled  bool              `default:"false"`
+	Exporter string            `default:"otlphttp"`
+	Endpoint string            `default:"localhost:4318"`
+	URLPath  string            `default:"/v1/traces"`
+	Insecure bool              `default:"false"`
+	Headers  map[string]string `default:"{}"`
+	Timeout  time.Duration     `default:"10s"`
+}
+
+var DefaultConfig = &Config{
+	Exporter: OTLPHTTPExporter,
+	Endpoint: "localhost:4318",
+	URLPath:  "/v1/traces",
+	Timeout:  10 * time.Second,
+}
+
+// NewExporter returns the span exporter selected by config.Exporter.
+// A nil exporter is returned for NoneExporter.
+func NewExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case OTLPHTTPExporter:
+		return newOTLPHTTPExporter(ctx, config)
+	case StdoutExporter:
+		return newStdoutExporter(os.Stdout)
+	case NoneExporter, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported exporter: %q", config.Exporter)
+	}
+}
+
+func newOTLPHTTPExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, error) {
+	var opts []otlptracehttp.Option
+
+	if config.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+	}
+	if config.URLPath != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(config.URLPath))
+	}
+	if config.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+	}
+	if config.Timeout > 0 {
+		opts = append(opts, otlptracehttp.WithTimeout(config.Timeout))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
+
+func newStdoutExporter(w io.Writer) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(w))
+}
+
+// NewResource returns a resource describing the application.
+func NewResource(info app.Info) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(info.Name()),
+	}
+
+	if info.Version() != "" {
+		attrs = append(attrs, semconv.ServiceVersion(info.Version()))
+	}
+	if info.ID() != "" {
+		attrs = append(attrs, semconv.ServiceInstanceID(info.ID()))
+	}
+	for k, v := range info.Metadata() {
+		attrs = append(attrs, attribute.String("app.metadata."+k, v))
+	}
+	if endpoints := info.Endpoint(); len(endpoints) > 0 {
+		attrs = append(attrs, attribute.StringSlice("app.endpoint", endpoints))
+	}
+
+	return resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, attrs...),
+	)
+}
+
+// NewTracerProvider returns a tracer provider exporting to exporter with
+// resource attributes taken from info. Spans are dropped if exporter is nil.
+func NewTracerProvider(info app.Info, exporter sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	res, err := NewResource(info)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
+}
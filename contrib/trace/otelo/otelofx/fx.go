@@ -0,0 +1,76 @@
+package otelofx
+
+import (
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+
+	"github.com/go-toho/toho"
+	"github.com/go-toho/toho/contrib/trace/otelo"
+	otelofxevent "github.com/go-toho/toho/contrib/trace/otelo/fxevent"
+	"github.com/go-toho/toho/pkg/fxtags"
+	"github.com/go-toho/toho/tohofx"
+)
+
+// NewCore returns the fx core with tracing of its lifecycle.
+func NewCore(opts ...otelo.Option) toho.Core {
+	return WrapCore(tohofx.NewCore(), opts...)
+}
+
+// WrapCore returns an fx based core with tracing of its lifecycle. Besides
+// the phase spans, it records a span for every fx constructor, invoke and
+// lifecycle hook, and provides the trace.TracerProvider to the application.
+//
+// The core installs its own fx event logger. Use SupplyFxEventLogger to keep
+// logging fx events.
+func WrapCore(core toho.Core, opts ...otelo.Option) toho.Core {
+	return &fxCore{Core: otelo.WrapCore(core, opts...)}
+}
+
+type fxCore struct {
+	*otelo.Core
+}
+
+func (c *fxCore) Init(opts *toho.CoreOptions) error {
+	opts.Options = append(slices.Clip(opts.Options),
+		FxEventLogger(c.Tracer()),
+		fx.Provide(c.tracerProvider),
+	)
+	return c.Core.Init(opts)
+}
+
+func (c *fxCore) tracerProvider() trace.TracerProvider {
+	if provider := c.TracerProvider(); provider != nil {
+		return provider
+	}
+	return noop.NewTracerProvider()
+}
+
+type eventLoggerParams struct {
+	fx.In
+
+	Next fxevent.Logger `name:"otel.FxEventLogger" optional:"true"`
+}
+
+// FxEventLogger records fx events as spans of tracer and forwards them to
+// the logger supplied with SupplyFxEventLogger.
+func FxEventLogger(tracer *otelo.Tracer) fx.Option {
+	return fx.WithLogger(func(p eventLoggerParams) fxevent.Logger {
+		return &otelofxevent.SpanLogger{Tracer: tracer, Next: p.Next}
+	})
+}
+
+// SupplyFxEventLogger provides the fx event logger the traced events are
+// forwarded to. The constructor may depend on other values of the
+// application, like fx.WithLogger.
+func SupplyFxEventLogger(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.ResultTags(fxtags.Named(otelo.NamedFxEventLogger)),
+		),
+	)
+}
@@ -0,0 +1,201 @@
+package otelofx_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-toho/toho"
+	"github.com/go-toho/toho/app"
+	"github.com/go-toho/toho/contrib/trace/otelo"
+	"github.com/go-toho/toho/contrib/trace/otelo/otelofx"
+)
+
+// collector is an in-process stand-in for an OTLP/HTTP collector.
+type collector struct {
+	mu        sync.Mutex
+	spans     map[string]string // span name -> trace id
+	resources map[string]string
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	t.Helper()
+
+	c := &collector{
+		spans:     map[string]string{},
+		resources: map[string]string{},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		c.mu.Lock()
+		for _, rs := range req.GetResourceSpans() {
+			for _, attr := range rs.GetResource().GetAttributes() {
+				c.resources[attr.GetKey()] = attr.GetValue().GetStringValue()
+			}
+			for _, ss := range rs.GetScopeSpans() {
+				for _, span := range ss.GetSpans() {
+					c.spans[span.GetName()] = string(span.GetTraceId())
+				}
+			}
+		}
+		c.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	return c, srv
+}
+
+func (c *collector) traceID(t *testing.T, name string) string {
+	t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, ok := c.spans[name]
+	if !ok {
+		t.Fatalf("span %q not exported, got %v", name, c.spans)
+	}
+	return id
+}
+
+func (c *collector) spanWithPrefix(t *testing.T, prefix string) string {
+	t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name := range c.spans {
+		if strings.HasPrefix(name, prefix) {
+			return name
+		}
+	}
+	t.Fatalf("no span with prefix %q exported, got %v", prefix, c.spans)
+	return ""
+}
+
+// discardLogger supplies the logger of the app, which no module provides.
+var discardLogger = fx.Supply(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+func TestCoreExportsLifecycleTraces(t *testing.T) {
+	c, srv := newCollector(t)
+
+	a := toho.New(
+		toho.AppCore(otelofx.NewCore(
+			otelo.WithConfig(otelo.Config{
+				Enabled:  true,
+				Exporter: otelo.OTLPHTTPExporter,
+				Endpoint: strings.TrimPrefix(srv.URL, "http://"),
+				Insecure: true,
+			}),
+		)),
+		toho.AppInfo(
+			app.Name("orders-api"),
+			app.Version("1.2.3"),
+		),
+		toho.Options(
+			discardLogger,
+			fx.Invoke(func(lc fx.Lifecycle) {
+				lc.Append(fx.StartStopHook(
+					func(context.Context) error { return nil },
+					func(context.Context) error { return nil },
+				))
+			}),
+		),
+	)
+
+	if err := a.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := a.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	startTrace := c.traceID(t, otelo.SpanStart)
+	stopTrace := c.traceID(t, otelo.SpanStop)
+	if startTrace == stopTrace {
+		t.Fatal("start and stop share a trace, want one trace each")
+	}
+
+	for name, want := range map[string]string{
+		otelo.PhaseInit:                 startTrace,
+		otelo.PhaseStart:                startTrace,
+		otelo.PhaseStop:                 stopTrace,
+		c.spanWithPrefix(t, "invoke "):  startTrace,
+		c.spanWithPrefix(t, "provide "): startTrace,
+		c.spanWithPrefix(t, "OnStart "): startTrace,
+		c.spanWithPrefix(t, "OnStop "):  stopTrace,
+	} {
+		if got := c.traceID(t, name); got != want {
+			t.Errorf("span %q is not part of the expected trace", name)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if got := c.resources["service.name"]; got != "orders-api" {
+		t.Errorf("service.name = %q, want orders-api", got)
+	}
+	if got := c.resources["service.version"]; got != "1.2.3" {
+		t.Errorf("service.version = %q, want 1.2.3", got)
+	}
+}
+
+func TestCoreForwardsFxEvents(t *testing.T) {
+	var events int
+	a := toho.New(
+		toho.AppCore(otelofx.NewCore(
+			otelo.WithConfig(otelo.Config{Enabled: true, Exporter: otelo.NoneExporter}),
+		)),
+		toho.Options(
+			discardLogger,
+			otelofx.SupplyFxEventLogger(func() fxevent.Logger {
+				return loggerFunc(func(fxevent.Event) { events++ })
+			}),
+		),
+	)
+
+	if err := a.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := a.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if events == 0 {
+		t.Fatal("no fx events forwarded to the supplied logger")
+	}
+}
+
+type loggerFunc func(fxevent.Event)
+
+func (f loggerFunc) LogEvent(e fxevent.Event) { f(e) }
@@ -0,0 +1,5 @@
+package otelo
+
+const (
+	NamedFxEventLogger = "otel.FxEventLogger"
+)
@@ -0,0 +1,152 @@
+package otelo
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName is the name of the tracer used for lifecycle spans.
+const instrumentationName = "github.com/go-toho/toho/contrib/trace/otelo"
+
+// Tracer records one trace per application start or stop. Each trace has a
+// root span for the operation and a child span for the current phase, which
+// is the parent of any span started from Context.
+type Tracer struct {
+	mu       sync.Mutex
+	tracer   trace.Tracer
+	root     trace.Span
+	rootCtx  context.Context
+	phase    trace.Span
+	phaseCtx context.Context
+}
+
+// NewTracer returns a tracer using provider. A nil provider disables tracing.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	t := &Tracer{}
+	t.SetTracerProvider(provider)
+	return t
+}
+
+// SetTracerProvider replaces the provider used for new spans.
+func (t *Tracer) SetTracerProvider(provider trace.TracerProvider) {
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tracer = provider.Tracer(instrumentationName)
+}
+
+// Begin starts a new trace for the operation name. Any operation still in
+// progress is ended first.
+func (t *Tracer) Begin(ctx context.Context, name string, opts ...trace.SpanStartOption) context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.endLocked(nil)
+
+	opts = append(opts, trace.WithNewRoot())
+	t.rootCtx, t.root = t.tracerLocked().Start(ctx, name, opts...)
+	return t.rootCtx
+}
+
+// BeginPhase starts a phase of the current operation. Any phase still in
+// progress is ended first.
+func (t *Tracer) BeginPhase(name string, opts ...trace.SpanStartOption) context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.endPhaseLocked(nil)
+
+	parent := t.rootCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	t.phaseCtx, t.phase = t.tracerLocked().Start(parent, name, opts...)
+	return t.phaseCtx
+}
+
+// EndPhase ends the current phase, recording err if it is non-nil.
+func (t *Tracer) EndPhase(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.endPhaseLocked(err)
+}
+
+// End ends the current phase and operation, recording err if it is non-nil.
+func (t *Tracer) End(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.endLocked(err)
+}
+
+// Context returns the context of the current phase, or of the current
+// operation between phases.
+func (t *Tracer) Context() context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case t.phaseCtx != nil:
+		return t.phaseCtx
+	case t.rootCtx != nil:
+		return t.rootCtx
+	default:
+		return context.Background()
+	}
+}
+
+// Start starts a span as a child of the span in ctx. Use Context to start
+// the span in the current phase.
+func (t *Tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.mu.Lock()
+	tracer := t.tracerLocked()
+	t.mu.Unlock()
+
+	return tracer.Start(ctx, name, opts...)
+}
+
+func (t *Tracer) tracerLocked() trace.Tracer {
+	if t.tracer == nil {
+		t.tracer = noop.NewTracerProvider().Tracer(instrumentationName)
+	}
+	return t.tracer
+}
+
+func (t *Tracer) endPhaseLocked(err error) {
+	if t.phase == nil {
+		return
+	}
+
+	EndSpan(t.phase, err)
+	t.phase, t.phaseCtx = nil, nil
+}
+
+func (t *Tracer) endLocked(err error) {
+	t.endPhaseLocked(err)
+
+	if t.root == nil {
+		return
+	}
+
+	EndSpan(t.root, err)
+	t.root, t.rootCtx = nil, nil
+}
+
+// EndSpan ends span, recording err if it is non-nil.
+func EndSpan(span trace.Span, err error, opts ...trace.SpanEndOption) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(opts...)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/go-toho/toho/app"
+	"github.com/go-toho/toho/config"
 )
 
 // CoreOptions struct holds the configuration options for the Core interface.
@@ -17,8 +18,13 @@ type CoreOptions struct {
 	App app.App
 
 	ConfigPointer any
+	ConfigLoader  config.Loader
 	LogPointer    any
 
+	// ConfigValue is a *config.Value of the config type, which the core
+	// keeps current when it reloads the config.
+	ConfigValue config.Subscriber
+
 	Options []any
 
 	StartTimeout time.Duration
@@ -48,14 +54,23 @@ type defaultCore struct{}
 var _ Core = (*defaultCore)(nil)
 
 func (defaultCore) Init(opts *CoreOptions) error {
-	if opts.ConfigPointer != nil {
-		if _, ok := opts.ConfigPointer.(*struct{}); !ok {
-			s := reflect.ValueOf(opts.ConfigPointer)
-			return fmt.Errorf("unsupported config type: %s", s.Type().String())
-		}
+	if opts.ConfigPointer == nil {
+		return nil
+	}
+	if _, ok := opts.ConfigPointer.(*struct{}); ok {
+		return nil
+	}
+
+	if opts.ConfigLoader == nil {
+		s := reflect.ValueOf(opts.ConfigPointer)
+		return fmt.Errorf("unsupported config type: %s, no config loader", s.Type().String())
+	}
+
+	if err := opts.ConfigLoader.Load(opts.ConfigPointer); err != nil {
+		return err
 	}
 
-	return nil
+	return config.Validate(opts.ConfigPointer)
 }
 
 func (defaultCore) Start(ctx context.Context) error {
@@ -15,9 +15,10 @@ import (
 	"github.com/go-toho/toho"
 	"github.com/go-toho/toho/app"
 	"github.com/go-toho/toho/config/configfx"
+	"github.com/go-toho/toho/contrib/core/features"
+	_ "github.com/go-toho/toho/contrib/core/features/featuresfx"
 	_ "github.com/go-toho/toho/contrib/log/slogo/slogofx"
 	"github.com/go-toho/toho/logger"
-	"github.com/go-toho/toho/logger/loggerfx"
 	"github.com/go-toho/toho/tohofx"
 )
 
@@ -26,7 +27,7 @@ type runtimeConfig struct {
 	Logger      logger.Config   `default:"{}"`
 	Metrics     metricsConfig   `default:"{}"`
 	HTTP        httpConfig      `default:"{}"`
-	Features    map[string]bool `default:"{\"metrics\":true}"`
+	Features    features.Config `default:"{}"`
 }
 
 type metricsConfig struct {
@@ -37,6 +38,8 @@ type httpConfig struct {
 	Addr string `default:"127.0.0.1:0"`
 }
 
+var metricsEnabled = features.Bool("metrics", false, "count the application runs")
+
 type metricsBundle struct {
 	runs atomic.Uint64
 }
@@ -50,9 +53,9 @@ func main() {
 		},
 		Metrics: metricsConfig{Namespace: "toho_fx_example"},
 		HTTP:    httpConfig{Addr: "127.0.0.1:0"},
-		Features: map[string]bool{
-			"metrics": true,
-		},
+		Features: features.Config{Flags: map[string]features.FlagConfig{
+			"metrics": {Rule: features.Rule{Value: "true"}},
+		}},
 	}
 
 	a := toho.NewC[runtimeConfig](
@@ -66,7 +69,6 @@ func main() {
 			fx.NopLogger,
 			configfx.SupplyConfig(&cfg),
 			configfx.ProvideAppConfig[runtimeConfig](),
-			loggerfx.SupplyFxSetupConfig(&cfg.Logger),
 			fx.Supply(&metricsBundle{}),
 			fx.Provide(
 				newHTTPServer,
@@ -90,7 +92,9 @@ func main() {
 }
 
 func runApplication(info app.Info, cfg runtimeConfig, log *slog.Logger, metrics *metricsBundle) {
-	metrics.runs.Add(1)
+	if metricsEnabled.Value(context.Background()) {
+		metrics.runs.Add(1)
+	}
 	log.Info(
 		"application wired",
 		slog.String("app", info.Name()),
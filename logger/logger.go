@@ -1,32 +1,206 @@
 package logger
 
+import (
+	"time"
+
+	"github.com/go-toho/toho/config"
+)
+
 // The log format can either be text or JSON.
 const (
 	JSONFormat = "json"
 	TextFormat = "text"
 )
 
+// The log output can either be stdout, stderr or the path of a file.
+const (
+	StdoutOutput = "stdout"
+	StderrOutput = "stderr"
+)
+
 // Config stores the config for the logger.
 type Config struct {
 	Level  string `default:"info"`
-	Format string `default:"json"`
+	Format string `default:"json" validate:"oneof=json text"`
 	Caller bool   `default:"false"`
+
+	// Context adds the request-scoped attributes of the record context,
+	// like its trace and request IDs, to the records.
+	Context bool `default:"true"`
+
+	// Levels overrides Level for the named loggers, by name, like
+	// "orders/db": "debug". A name also matches the names it prefixes on
+	// their / separated elements, the longest one winning.
+	Levels map[string]string
+
+	// Output is where the logs are written: stdout, stderr or the path of
+	// a file, rotated as set by Rotation.
+	Output   string `default:"stdout"`
+	Rotation Rotation
+
+	// Handlers are the handlers the logs are dispatched to, like default
+	// for the one writing to Output, all of them if empty.
+	Handlers []HandlerConfig
+
+	// Redact redacts the sensitive values of the records.
+	Redact Redaction
+
+	// Sampling samples the repeated records.
+	Sampling Sampling
+
+	// Async handles the records in the background.
+	Async Async
+}
+
+// HandlerConfig selects a handler of the logs.
+type HandlerConfig struct {
+	// Name is the name of the handler, like default.
+	Name string `validate:"required"`
+
+	// Level is the minimum level of the records dispatched to the handler,
+	// on top of its own, if not empty.
+	Level string
+}
+
+// The overflow policy of an async handler can either block the logging
+// goroutine, drop the oldest buffered record or drop the new one.
+const (
+	BlockOverflow      = "block"
+	DropOldestOverflow = "drop-oldest"
+	DropNewestOverflow = "drop-newest"
+)
+
+// Async stores the buffering of the records handled in the background.
+type Async struct {
+	// Enabled buffers up to Buffer records, handled in the background,
+	// and applies the Overflow policy when the buffer is full.
+	Enabled  bool   `default:"false"`
+	Buffer   int    `default:"1024" validate:"min=0"`
+	Overflow string `default:"block" validate:"oneof=block drop-oldest drop-newest"`
+}
+
+// Sampling stores the sampling of repeated records, like zap's sampler.
+type Sampling struct {
+	// Enabled keeps, every Interval, the First records of a level and
+	// message, then 1 in Thereafter, and drops the others, reporting their
+	// count every ReportInterval.
+	Enabled        bool          `default:"false"`
+	Interval       time.Duration `default:"1s"`
+	ReportInterval time.Duration `default:"1m"`
+	SamplingRule
+
+	// Levels overrides the rule by level, like error.
+	Levels map[string]SamplingRule
+}
+
+// SamplingRule stores the sampling of the records of a level. A rule with
+// First 0 samples none, and one with Thereafter 0 drops every record after
+// the First ones.
+type SamplingRule struct {
+	First      int `default:"100" validate:"min=0"`
+	Thereafter int `default:"100" validate:"min=0"`
+}
+
+// Redaction stores the redaction of sensitive values.
+//
+// Only slogo redacts: zapo ignores it, so a zap logger writes the values a
+// slog logger of the same config redacts.
+type Redaction struct {
+	// Enabled replaces with Replacement the values of the attributes with
+	// a key holding the segments of one of the keys, like db_password or
+	// dbPassword for password, ignoring case, and the parts of the
+	// messages, strings, errors and fmt.Stringers matching one of the
+	// value regexps.
+	Enabled bool `default:"true"`
+
+	// Keys extend DefaultRedactKeys.
+	Keys []string
+
+	// Values are the value regexps, matched against every message and
+	// string of the records, which costs on every log call. DefaultValues
+	// adds DefaultRedactValues, which also redact the 13 to 19 digits
+	// strings like ids and timestamps.
+	Values        []string
+	DefaultValues bool
+
+	// Replacement replaces the redacted values, DefaultRedactReplacement if
+	// empty.
+	Replacement string
+}
+
+// Defaults of the redaction: the keys of credentials, and the regexps of
+// card numbers and emails, applied with DefaultValues only.
+var (
+	DefaultRedactKeys = []string{
+		"password", "passwd", "secret", "token", "authorization", "cookie", "apikey", "api_key",
+	}
+
+	DefaultRedactValues = []string{
+		`\b(?:\d[ -]?){12,18}\d\b`,
+		`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	}
+)
+
+// DefaultRedactReplacement replaces the redacted values by default.
+const DefaultRedactReplacement = "[REDACTED]"
+
+// Rotation stores the rotation of a file output. Zero values disable their
+// setting.
+type Rotation struct {
+	// MaxSize rotates the file before it exceeds MaxSize megabytes.
+	MaxSize int `validate:"min=0"`
+
+	// Interval rotates the file at every multiple of Interval, in UTC, like
+	// 24h for daily files.
+	Interval time.Duration
+
+	// MaxAge removes the rotated files older than MaxAge, and MaxBackups
+	// the ones beyond the MaxBackups most recent.
+	MaxAge     time.Duration
+	MaxBackups int `validate:"min=0"`
+
+	// Compress compresses the rotated files with gzip.
+	Compress bool
 }
 
 var (
 	DefaultConfig = &Config{
-		Level:  "info",
-		Format: JSONFormat,
-		Caller: false,
+		Level:   "info",
+		Format:  JSONFormat,
+		Caller:  false,
+		Context: true,
+		Output:  StdoutOutput,
+		Redact:  Redaction{Enabled: true},
 	}
 
 	DebugTextConfig = &Config{
-		Level:  "debug",
-		Format: TextFormat,
-		Caller: true,
+		Level:   "debug",
+		Format:  TextFormat,
+		Caller:  true,
+		Context: true,
+		Output:  StdoutOutput,
+		Redact:  Redaction{Enabled: true},
 	}
 )
 
+// DefaultConfigFor returns the default config of profile: debug text
+// logs for the local profile, DefaultConfig otherwise.
+func DefaultConfigFor(profile string) *Config {
+	if profile == config.ProfileLocal {
+		return DebugTextConfig
+	}
+	return DefaultConfig
+}
+
+// ProfileDefault implements config.ProfileDefaulter, so that fields not set
+// by any source get the defaults of the profile.
+func (c Config) ProfileDefault(profile string) any {
+	if profile == config.ProfileLocal {
+		return DebugTextConfig
+	}
+	return nil
+}
+
 // WithLevel returns a new config with overridden value.
 func (c *Config) WithLevel(level string) *Config {
 	c.Level = level
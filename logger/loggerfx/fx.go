@@ -3,11 +3,14 @@ package loggerfx
 import (
 	"go.uber.org/fx"
 
+	"github.com/go-toho/toho/config"
+	"github.com/go-toho/toho/config/configfx"
 	"github.com/go-toho/toho/logger"
 	"github.com/go-toho/toho/pkg/fxtags"
 )
 
 var Module = fx.Module("logger",
+	configfx.SupplyKey(config.KeyFor[logger.Config](logger.ConfigKey)),
 	provideFxSetupConfigPointer,
 	provideConfigPointer,
 	provideConfig,
@@ -16,20 +19,28 @@ var Module = fx.Module("logger",
 var (
 	provideFxSetupConfigPointer = fx.Provide(
 		fx.Annotate(
-			func(config any) *logger.Config {
-				return loggerConfigOrDefault(config)
+			func(cfg any, root any, profile string) *logger.Config {
+				return loggerConfigOrDefault(cfg, root, profile)
 			},
-			fx.ParamTags(fxtags.NamedOptional(logger.NamedFxSetupConfig)),
+			fx.ParamTags(
+				fxtags.NamedOptional(logger.NamedFxSetupConfig),
+				fxtags.NamedOptional(config.NamedConfigPointerOut),
+				fxtags.NamedOptional(config.NamedProfile),
+			),
 			fx.ResultTags(fxtags.Named(logger.NamedFxSetupConfig)),
 		),
 	)
 
 	provideConfigPointer = fx.Provide(
 		fx.Annotate(
-			func(config any) *logger.Config {
-				return loggerConfigOrDefault(config)
+			func(cfg any, root any, profile string) *logger.Config {
+				return loggerConfigOrDefault(cfg, root, profile)
 			},
-			fx.ParamTags(fxtags.NamedOptional(logger.NamedConfig)),
+			fx.ParamTags(
+				fxtags.NamedOptional(logger.NamedConfig),
+				fxtags.NamedOptional(config.NamedConfigPointerOut),
+				fxtags.NamedOptional(config.NamedProfile),
+			),
 			fx.ResultTags(fxtags.Named(logger.NamedConfig)),
 		),
 	)
@@ -51,9 +62,9 @@ var (
 	)
 )
 
-func loggerConfigOrDefault(config any) *logger.Config {
-	if config != nil {
-		switch v := config.(type) {
+func loggerConfigOrDefault(cfg any, root any, profile string) *logger.Config {
+	if cfg != nil {
+		switch v := cfg.(type) {
 		case *logger.Config:
 			return v
 		case logger.Config:
@@ -62,7 +73,10 @@ func loggerConfigOrDefault(config any) *logger.Config {
 			break
 		}
 	}
-	return logger.DefaultConfig
+	if v, ok := config.BindSection[logger.Config](root, logger.ConfigKey); ok {
+		return v
+	}
+	return logger.DefaultConfigFor(profile)
 }
 
 func SupplyFxSetupConfig(config *logger.Config) fx.Option {
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"io"
+	"os"
+
+	"github.com/go-toho/toho/pkg/rotate"
+)
+
+// OpenOutput opens the output of config, stdout if empty. Closing stdout
+// and stderr outputs does nothing, and closing file outputs flushes and
+// closes them.
+func OpenOutput(config Config) (io.WriteCloser, error) {
+	switch config.Output {
+	case "", StdoutOutput:
+		return nopCloser{os.Stdout}, nil
+	case StderrOutput:
+		return nopCloser{os.Stderr}, nil
+	}
+
+	return rotate.Open(config.Output, rotate.Options{
+		MaxSize:    int64(config.Rotation.MaxSize) << 20,
+		Interval:   config.Rotation.Interval,
+		MaxAge:     config.Rotation.MaxAge,
+		MaxBackups: config.Rotation.MaxBackups,
+		Compress:   config.Rotation.Compress,
+	})
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -1,6 +1,9 @@
 package logger
 
 const (
+	// ConfigKey is the key of the logger section in the root config.
+	ConfigKey = "logger"
+
 	NamedFxSetupConfig = "logger.FxSetupConfig"
 	NamedConfig        = "logger.Config"
 )
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/go-toho/toho/app"
+	"github.com/go-toho/toho/config"
 )
 
 // Option is an application option.
@@ -18,6 +19,7 @@ type options struct {
 
 	core          Core
 	configPointer any
+	configLoader  config.Loader
 	logger        any
 	options       []any
 
@@ -52,6 +54,13 @@ func Config[C any](c *C) Option {
 	return func(o *options) { o.configPointer = c }
 }
 
+// ConfigLoader loads the application's config on start, before the
+// BeforeStart functions run. It is required by the default core for any
+// config type but struct{}.
+func ConfigLoader(l config.Loader) Option {
+	return func(o *options) { o.configLoader = l }
+}
+
 // Logger with app logger.
 func Logger(l any) Option {
 	return func(o *options) { o.logger = l }
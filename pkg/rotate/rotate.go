@@ -0,0 +1,351 @@
+// Package rotate writes to a file rotated by size or time, keeping a number
+// of rotated files, optionally compressed.
+//
+// A file app.log is rotated by renaming it to app-20060102T150405.000.log,
+// with the time of the rotation, and writing to a new app.log. A counter
+// like app-20060102T150405.000-1.log tells apart the files rotated in the
+// same millisecond.
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeFormat is the format of the rotation time in the rotated file names.
+const timeFormat = "20060102T150405.000"
+
+// compressSuffix is the suffix of the compressed rotated files.
+const compressSuffix = ".gz"
+
+// Options are the rotation options of a file. Zero values disable their
+// option.
+type Options struct {
+	// MaxSize rotates the file before a write makes it exceed MaxSize bytes.
+	MaxSize int64
+
+	// Interval rotates the file on the first write after every multiple of
+	// Interval since the zero time, like 24h for daily files, in UTC.
+	Interval time.Duration
+
+	// MaxAge removes the rotated files older than MaxAge.
+	MaxAge time.Duration
+
+	// MaxBackups removes the rotated files beyond the MaxBackups most recent.
+	MaxBackups int
+
+	// Compress compresses the rotated files with gzip.
+	Compress bool
+}
+
+// File is a rotated file, safe for concurrent use.
+type File struct {
+	path string
+	opts Options
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	next time.Time
+
+	// millMu serializes the compression and removal of the rotated files,
+	// which run in the background.
+	millMu sync.Mutex
+	mills  sync.WaitGroup
+
+	now    func() time.Time
+	rename func(oldpath, newpath string) error
+}
+
+// Open opens the file at path for appending, creating it and its directory
+// if needed, and rotates it as set by opts.
+func Open(path string, opts Options) (*File, error) {
+	f := &File{path: path, opts: opts, now: time.Now, rename: os.Rename}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes p to the file, rotating it first if needed. If the rotation
+// fails, p is still written to the file, and the rotation error returned.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	var rotateErr error
+	now := f.now()
+	if (f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize) ||
+		(!f.next.IsZero() && !now.Before(f.next)) {
+		rotateErr = f.rotate(now)
+		if f.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+// Sync commits the file to stable storage.
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+// Rotate rotates the file now.
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate(f.now())
+}
+
+// Close syncs and closes the file, after waiting for the background
+// compression and removal of the rotated files.
+func (f *File) Close() error {
+	f.mu.Lock()
+	file := f.file
+	f.file = nil
+	f.mu.Unlock()
+
+	f.mills.Wait()
+
+	if file == nil {
+		return os.ErrClosed
+	}
+	syncErr := file.Sync()
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+func (f *File) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("rotate: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.next = time.Time{}
+	if f.opts.Interval > 0 {
+		// an existing file written in a past interval rotates on the first write
+		start := f.now()
+		if f.size > 0 {
+			start = info.ModTime()
+		}
+		f.next = start.UTC().Truncate(f.opts.Interval).Add(f.opts.Interval)
+	}
+	return nil
+}
+
+// rotate renames the file after the time now and opens a new one. If
+// either fails, it reopens the file it was writing to, if possible.
+func (f *File) rotate(now time.Time) error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+	f.file = nil
+
+	backup := f.backupName(now)
+	if err := f.rename(f.path, backup); err != nil && !os.IsNotExist(err) {
+		return errors.Join(fmt.Errorf("rotate: %w", err), f.open())
+	}
+	if err := f.open(); err != nil {
+		return errors.Join(err, f.reopen(backup))
+	}
+
+	if f.opts.Compress || f.opts.MaxAge > 0 || f.opts.MaxBackups > 0 {
+		f.mills.Add(1)
+		go func() {
+			defer f.mills.Done()
+			f.mill(now)
+		}()
+	}
+	return nil
+}
+
+// reopen reopens the rotated file at backup for appending, when the file
+// could not be opened again after its rotation.
+func (f *File) reopen(backup string) error {
+	file, err := os.OpenFile(backup, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("rotate: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// backupName returns the name of the file rotated at t, with a counter if
+// a file rotated in the same millisecond exists.
+func (f *File) backupName(t time.Time) string {
+	dir, prefix, ext := f.split()
+	base := prefix + t.UTC().Format(timeFormat)
+
+	name := filepath.Join(dir, base+ext)
+	for n := 1; exists(name) || exists(name+compressSuffix); n++ {
+		name = filepath.Join(dir, base+"-"+strconv.Itoa(n)+ext)
+	}
+	return name
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// split returns the directory of the file, the prefix of its rotated files
+// and its extension.
+func (f *File) split() (dir, prefix, ext string) {
+	dir, name := filepath.Split(f.path)
+	ext = filepath.Ext(name)
+	return dir, strings.TrimSuffix(name, ext) + "-", ext
+}
+
+// backup is a rotated file.
+type backup struct {
+	path string
+	time time.Time
+	n    int
+}
+
+// backups returns the rotated files, the most recent first.
+func (f *File) backups() ([]backup, error) {
+	dir, prefix, ext := f.split()
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressSuffix)
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		ts = strings.TrimSuffix(ts, ext)
+
+		var n int
+		if i := strings.LastIndexByte(ts, '-'); i >= 0 {
+			if n, err = strconv.Atoi(ts[i+1:]); err != nil || n <= 0 {
+				continue
+			}
+			ts = ts[:i]
+		}
+		t, err := time.Parse(timeFormat, ts)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), time: t, n: n})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].time.Equal(backups[j].time) {
+			return backups[i].n > backups[j].n
+		}
+		return backups[i].time.After(backups[j].time)
+	})
+	return backups, nil
+}
+
+// mill removes the expired rotated files and compresses the others. Errors
+// are ignored, the files being left for the next rotation.
+func (f *File) mill(now time.Time) {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	backups, err := f.backups()
+	if err != nil {
+		return
+	}
+
+	for i, b := range backups {
+		if (f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups) ||
+			(f.opts.MaxAge > 0 && now.Sub(b.time) > f.opts.MaxAge) {
+			_ = os.Remove(b.path)
+			continue
+		}
+		if f.opts.Compress && !strings.HasSuffix(b.path, compressSuffix) {
+			_ = compress(b.path)
+		}
+	}
+}
+
+// compress replaces the file at path with its gzip compression.
+func compress(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,225 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	f, err := Open(path, Options{MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	mustWrite(t, f, "12345678\n")
+	now = now.Add(time.Second)
+	mustWrite(t, f, "abcdefgh\n")
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, path); got != "abcdefgh\n" {
+		t.Errorf("expected current file to hold the last write, got %q", got)
+	}
+	backup := filepath.Join(filepath.Dir(path), "app-20261019T120001.000.log")
+	if got := readFile(t, backup); got != "12345678\n" {
+		t.Errorf("expected rotated file to hold the first write, got %q", got)
+	}
+}
+
+func TestRotateByInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	f, err := Open(path, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	now := time.Now().UTC()
+	f.now = func() time.Time { return now }
+
+	mustWrite(t, f, "first\n")
+	mustWrite(t, f, "second\n")
+	if backups, _ := f.backups(); len(backups) != 0 {
+		t.Fatalf("expected no rotation within the interval, got %v", backups)
+	}
+
+	now = now.Add(time.Hour)
+	mustWrite(t, f, "third\n")
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || readFile(t, backups[0].path) != "first\nsecond\n" {
+		t.Errorf("expected one rotation after the interval, got %v", backups)
+	}
+}
+
+func TestRotateRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	f, err := Open(path, Options{MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		mustWrite(t, f, strings.Repeat("x", i+1))
+		now = now.Add(time.Minute)
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		f.mills.Wait()
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 rotated files, got %v", backups)
+	}
+	for i, want := range []string{"xxxx", "xxx"} {
+		if !strings.HasSuffix(backups[i].path, compressSuffix) {
+			t.Errorf("expected %s to be compressed", backups[i].path)
+			continue
+		}
+		if got := readGzip(t, backups[i].path); got != want {
+			t.Errorf("expected %s to hold %q, got %q", backups[i].path, want, got)
+		}
+	}
+
+	if _, err := f.Write([]byte("closed")); err == nil {
+		t.Error("expected write after close to fail")
+	}
+}
+
+func TestRotateInTheSameMillisecond(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	f, err := Open(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	for _, s := range []string{"first", "second", "third"} {
+		mustWrite(t, f, s)
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, b := range backups {
+		got = append(got, readFile(t, b.path))
+	}
+	if strings.Join(got, ",") != "third,second,first" {
+		t.Errorf("expected every rotated file kept, most recent first, got %v", got)
+	}
+}
+
+func TestRotateFailureKeepsWriting(t *testing.T) {
+	tests := []struct {
+		name   string
+		rename func(oldpath, newpath string) error
+	}{
+		{
+			name:   "rename",
+			rename: func(string, string) error { return os.ErrPermission },
+		},
+		{
+			name: "open",
+			rename: func(oldpath, newpath string) error {
+				if err := os.Rename(oldpath, newpath); err != nil {
+					return err
+				}
+				// a directory in the way of the new file
+				return os.Mkdir(oldpath, 0o755)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+
+			f, err := Open(path, Options{MaxSize: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			f.rename = tt.rename
+
+			mustWrite(t, f, "12345678\n")
+			if _, err := f.Write([]byte("abcdefgh\n")); err == nil {
+				t.Fatal("expected the rotation error")
+			}
+
+			f.mu.Lock()
+			name := f.file.Name()
+			f.mu.Unlock()
+			if got := readFile(t, name); got != "12345678\nabcdefgh\n" {
+				t.Errorf("expected the writes to go on after the failed rotation, got %q", got)
+			}
+		})
+	}
+}
+
+func mustWrite(t *testing.T, w io.Writer, s string) {
+	t.Helper()
+	if _, err := io.WriteString(w, s); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
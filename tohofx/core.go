@@ -34,6 +34,12 @@ func (s *fxCore) Init(opts *toho.CoreOptions) error {
 		if _, ok := opts.ConfigPointer.(*struct{}); !ok {
 			fxOptions = append(fxOptions, configfx.Module)
 			fxOptions = append(fxOptions, configfx.SupplyConfigPointer(opts.ConfigPointer))
+			if opts.ConfigLoader != nil {
+				fxOptions = append(fxOptions, configfx.SupplyLoader(opts.ConfigLoader))
+			}
+			if opts.ConfigValue != nil {
+				fxOptions = append(fxOptions, configfx.SupplyValue(opts.ConfigValue))
+			}
 		}
 	}
 
@@ -62,6 +68,7 @@ func (s *fxCore) Init(opts *toho.CoreOptions) error {
 
 	s.instance = fx.New(
 		appfx.ProvideApp(&opts.App),
+		configfx.DetectProfile,
 		loggerfx.Module,
 		ProvideRegistered(),
 		fx.Options(fxOptions...),